@@ -0,0 +1,80 @@
+package go_abi_param
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"math/big"
+	"strings"
+)
+
+// AddressOptions 严格模式下 address 的校验规则，见 SetStrictAddress
+type AddressOptions struct {
+	ChainID   *big.Int // 非空时按 EIP-1191 校验带链 ID 的 checksum，eg: RSK 为 30
+	AllowZero bool     // 是否接受零地址
+}
+
+// ChecksumError 地址大小写混合但 checksum 不正确，Suggest 为正确的写法
+type ChecksumError struct {
+	Value   string
+	Suggest string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("abi: invalid address checksum %s, did you mean %s?", e.Value, e.Suggest)
+}
+
+// SetStrictAddress 开启严格的地址校验：
+// 1. 必须是 0x 开头的 20 字节十六进制，不再截断或补零
+// 2. 大小写混合时必须是正确的 EIP-55 checksum（设置了 ChainID 时为 EIP-1191），全小写或全大写不校验
+// 3. 除非 AllowZero，否则不接受零地址，包括 SetResolver 解析出的地址
+func (ap *AbiParam) SetStrictAddress(opts AddressOptions) *AbiParam {
+	ap.address = &opts
+	return ap
+}
+
+// readStrictAddress 按 opts 校验并解析地址
+func readStrictAddress(value string, opts *AddressOptions) (common.Address, error) {
+	if !strings.HasPrefix(value, "0x") && !strings.HasPrefix(value, "0X") {
+		return common.Address{}, fmt.Errorf("abi: address %s should start with 0x", value)
+	}
+	b, err := hex.DecodeString(value[2:])
+	if err != nil || len(b) != common.AddressLength {
+		return common.Address{}, fmt.Errorf("abi: address %s is not 20 bytes hex", value)
+	}
+	addr := common.BytesToAddress(b)
+	if addr == (common.Address{}) && !opts.AllowZero {
+		return common.Address{}, fmt.Errorf("abi: zero address is not allowed")
+	}
+
+	digits := value[2:]
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return addr, nil
+	}
+	if want := checksumAddress(addr, opts.ChainID); digits != want[2:] {
+		return common.Address{}, &ChecksumError{Value: value, Suggest: want}
+	}
+	return addr, nil
+}
+
+// checksumAddress 返回 EIP-55 格式的地址，chainID 非空时按 EIP-1191 把链 ID 计入哈希
+func checksumAddress(addr common.Address, chainID *big.Int) string {
+	if chainID == nil {
+		return addr.Hex()
+	}
+	lower := hex.EncodeToString(addr[:])
+	hash := crypto.Keccak256([]byte(chainID.String() + "0x" + lower))
+
+	buf := []byte(lower)
+	for i := range buf {
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if buf[i] > '9' && nibble&0xf >= 8 {
+			buf[i] -= 'a' - 'A'
+		}
+	}
+	return "0x" + string(buf)
+}
@@ -0,0 +1,129 @@
+package go_abi_param
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"strings"
+)
+
+// ArgumentError 是参数列表中某一个参数的解析错误
+type ArgumentError struct {
+	Index int    // 参数位置，从 0 开始
+	Name  string // 参数名，签名中未命名时为空
+	Err   error
+}
+
+func (e *ArgumentError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("argument %d: %s", e.Index, e.Err)
+	}
+	return fmt.Sprintf("argument %d (%s): %s", e.Index, e.Name, e.Err)
+}
+
+func (e *ArgumentError) Unwrap() error {
+	return e.Err
+}
+
+// ArgumentErrors 收集参数列表中所有解析失败的参数
+type ArgumentErrors []*ArgumentError
+
+func (e ArgumentErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap 使 errors.As 可以直接取出其中的 *ArgumentError 与 *ParseError
+func (e ArgumentErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// ParseArguments 一次解析整个参数列表，返回值可以直接交给 abi.Arguments.Pack
+// args 为 abi.Arguments 或参数类型列表，eg: "(address,uint256[],bool)"、"(address to,uint256 amount)"
+// values 为 []string（每个参数一个值），或包含全部参数的字符串，eg: "0xabc,[1,2],true"，
+// 或 json.RawMessage，eg: {"to":"0xabc","amounts":["1000","2000"],"flag":true}，见 NewAbiParamJSON
+// 解析失败的参数以 ArgumentErrors 返回，每个 ArgumentError 带有参数位置与名字
+func ParseArguments(args interface{}, values interface{}) ([]interface{}, error) {
+	return defaultParser.ParseArguments(args, values)
+}
+
+// toArguments 返回参数列表与其中的定点数，只有字符串形式的参数列表可以包含定点数
+func toArguments(args interface{}) (abi.Arguments, fixedTypes, error) {
+	switch a := args.(type) {
+	case abi.Arguments:
+		return a, nil, checkArgumentsSize(a)
+	case []abi.Argument:
+		return a, nil, checkArgumentsSize(a)
+	case string:
+		return newArguments(a)
+	default:
+		return nil, nil, fmt.Errorf("args should be abi.Arguments or string, got %T", args)
+	}
+}
+
+// parseArguments 逐个解析参数，每个参数一个 value 字符串
+func (ap *AbiParam) parseArguments(arguments abi.Arguments, values []string) ([]interface{}, error) {
+	if len(values) != len(arguments) {
+		return nil, fmt.Errorf("want %d arguments, got %d", len(arguments), len(values))
+	}
+
+	var errs ArgumentErrors
+	ret := make([]interface{}, len(values))
+	for i, arg := range arguments {
+		v, err := ap.parseType(&arguments[i].Type, values[i])
+		if err != nil {
+			errs = append(errs, &ArgumentError{Index: i, Name: arg.Name, Err: err})
+			continue
+		}
+		ret[i] = v
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return ret, nil
+}
+
+// parseArgumentString 解析写在一个字符串中的参数列表，最外层的括号可以省略，
+// 错误中的 offset 相对于整个字符串
+func (ap *AbiParam) parseArgumentString(arguments abi.Arguments, value string) ([]interface{}, error) {
+	root, err := parseContainer(value, nodeTuple, ap.limits)
+	if err != nil {
+		return nil, err
+	}
+	if len(arguments) == 1 && arguments[0].Type.T == abi.TupleTy {
+		// 只有一个 tuple 参数时，value 本身也可以是这个 tuple，eg: ((uint256)) 的 value 可以是 (5) 或 ((5))，
+		// 只有一个成员时两种读法都可能成立，先按参数列表读，失败时再把 value 当作 tuple
+		wrapped := &node{kind: nodeTuple, elems: []*node{root}}
+		if len(root.elems) != 1 {
+			root = wrapped
+		} else if v, err := ap.parseNode(&arguments[0].Type, root.elems[0]); err == nil {
+			return []interface{}{v}, nil
+		} else if v, err := ap.parseNode(&arguments[0].Type, root); err == nil {
+			return []interface{}{v}, nil
+		}
+	}
+	if len(root.elems) != len(arguments) {
+		return nil, fmt.Errorf("want %d arguments, got %d", len(arguments), len(root.elems))
+	}
+
+	var errs ArgumentErrors
+	ret := make([]interface{}, len(arguments))
+	for i, arg := range arguments {
+		v, err := ap.parseNode(&arguments[i].Type, root.elems[i])
+		if err != nil {
+			errs = append(errs, &ArgumentError{Index: i, Name: arg.Name, Err: err})
+			continue
+		}
+		ret[i] = v
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return ret, nil
+}
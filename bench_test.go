@@ -0,0 +1,197 @@
+package go_abi_param
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// joinN 把 n 个 item(i) 用逗号连接并加上括号，eg: [0,1,2]
+func joinN(open, close string, n int, item func(i int) string) string {
+	var sb strings.Builder
+	sb.WriteString(open)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(item(i))
+	}
+	sb.WriteString(close)
+	return sb.String()
+}
+
+func benchUint(i int) string {
+	return strconv.Itoa(i * 1000003)
+}
+
+func benchAddress(i int) string {
+	return "0x" + strings.Repeat("0", 32) + strconv.FormatInt(int64(0x10000000+i), 16)
+}
+
+// nestedValue 返回 depth 层、每层 2 个元素的数组，eg: depth 为 2 时 [[7,7],[7,7]]
+func nestedValue(depth int) string {
+	if depth == 0 {
+		return "7"
+	}
+	inner := nestedValue(depth - 1)
+	return "[" + inner + "," + inner + "]"
+}
+
+type benchCase struct {
+	name  string
+	blob  string
+	value string
+}
+
+var (
+	scalarCases = []benchCase{
+		{"uint256", "uint256", "115792089237316195423570985008687907853269984665640564039457584007913129639935"},
+		{"uint8", "uint8", "255"},
+		{"int256 ether", "int256", "-1.5ether"},
+		{"address", "address", "0x00000000006c3852cbef3e08e8df289169ede581"},
+		{"bool", "bool", "true"},
+		{"bytes32", "bytes32", "0x" + strings.Repeat("ab", 32)},
+		{"string", "string", "hello, world"},
+	}
+	largeCases = []benchCase{
+		{"uint256[10000]", "uint256[]", joinN("[", "]", 10000, benchUint)},
+		{"address[10000]", "address[]", joinN("[", "]", 10000, benchAddress)},
+		{"(address,uint256)[10000]", "(address,uint256)[]", joinN("[", "]", 10000, func(i int) string {
+			return "(" + benchAddress(i) + "," + benchUint(i) + ")"
+		})},
+	}
+	nestedCases = []benchCase{
+		{"int8[2][2][2]", "int8[2][2][2]", nestedValue(3)},
+		{"int8[2][2][2][2][2][2][2][2]", "int8[2][2][2][2][2][2][2][2]", nestedValue(8)},
+		{"uint256[][][]", "uint256[][][]", joinN("[", "]", 10, func(int) string {
+			return joinN("[", "]", 10, func(int) string { return joinN("[", "]", 100, benchUint) })
+		})},
+	}
+	bytesCases = []benchCase{
+		{"bytes 64KiB", "bytes", "0x" + strings.Repeat("5a", 64<<10)},
+		{"string 64KiB", "string", strings.Repeat("go-abi-param ", 5000)},
+	}
+)
+
+func runBenchCases(b *testing.B, cases []benchCase) {
+	for _, bc := range cases {
+		ap := defaultParser.newParam(bc.blob, bc.value)
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(bc.value)))
+			for i := 0; i < b.N; i++ {
+				if _, err := ap.Parse(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParse_Scalar(b *testing.B) {
+	runBenchCases(b, scalarCases)
+}
+
+func BenchmarkParse_LargeArray(b *testing.B) {
+	runBenchCases(b, largeCases)
+}
+
+func BenchmarkParse_Nested(b *testing.B) {
+	runBenchCases(b, nestedCases)
+}
+
+func BenchmarkParse_LongBytes(b *testing.B) {
+	runBenchCases(b, bytesCases)
+}
+
+func BenchmarkParseJSON_LargeArray(b *testing.B) {
+	value := joinN("[", "]", 10000, func(i int) string { return `"` + benchUint(i) + `"` })
+	ap := defaultParser.newParam("uint256[]", value)
+	ap.json = true
+	b.ReportAllocs()
+	b.SetBytes(int64(len(value)))
+	for i := 0; i < b.N; i++ {
+		if _, err := ap.Parse(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkParse_NewTypePerElem 模拟旧实现中每个元素都把类型转回字符串再 abi.NewType 的开销，
+// 与 BenchmarkParse_LargeArray/uint256[10000] 对比
+func BenchmarkParse_NewTypePerElem(b *testing.B) {
+	value := largeCases[0].value
+	ap := defaultParser.newParam("uint256[]", value)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		typ, _ := newType("uint256[]")
+		root, err := parseContainer(value, nodeList, ap.limits)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, n := range root.elems {
+			elem, err := newType(typ.Elem.String())
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := ap.parseScalar(&elem.Type, n.text); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkTypeOf(b *testing.B) {
+	blob := "(address to,uint256[] amounts,(bytes32,string)[2] meta)[]"
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := cachedType(blob); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := newType(blob); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// TestParse_AllocBudget 检查热路径的内存分配次数不超过 README 中的预算：
+// 每个 uint256 元素 2 次（*big.Int 与其数据），每个 address 元素 1 次，
+// 每个数组或 tuple 3 次，bytes 与 string 与长度无关
+func TestParse_AllocBudget(t *testing.T) {
+	const n = 1000
+	tests := []struct {
+		name   string
+		blob   string
+		value  string
+		budget float64
+	}{
+		{"uint256[]", "uint256[]", joinN("[", "]", n, benchUint), 2*n + 64},
+		{"address[]", "address[]", joinN("[", "]", n, benchAddress), 1*n + 64},
+		{"(address,uint256)[]", "(address,uint256)[]", joinN("[", "]", n, func(i int) string {
+			return "(" + benchAddress(i) + "," + benchUint(i) + ")"
+		}), (1+2+3)*n + 64},
+		{"int8[2][2][2][2][2][2][2][2]", "int8[2][2][2][2][2][2][2][2]", nestedValue(8), 3*255 + 2*256 + 64},
+		{"bytes", "bytes", "0x" + strings.Repeat("5a", 64<<10), 4},
+		{"string", "string", strings.Repeat("go-abi-param ", 5000), 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ap := defaultParser.newParam(tt.blob, tt.value)
+			allocs := testing.AllocsPerRun(10, func() {
+				if _, err := ap.Parse(); err != nil {
+					t.Fatal(err)
+				}
+			})
+			if allocs > tt.budget {
+				t.Errorf("%s: %.0f allocs, budget %.0f", tt.name, allocs, tt.budget)
+			}
+		})
+	}
+}
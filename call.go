@@ -0,0 +1,283 @@
+package go_abi_param
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"strings"
+)
+
+// EncodeCall 按函数签名解析参数，返回 4 字节 selector + abi 编码后的 calldata
+// eg: EncodeCall("transfer(address,uint256)", "0xabc...", "1e18")
+func EncodeCall(signature string, values ...string) ([]byte, error) {
+	return defaultParser.EncodeCall(signature, values...)
+}
+
+// EncodeCall 按 p 的设置解析参数并编码 calldata，见 EncodeCall
+func (p *Parser) EncodeCall(signature string, values ...string) (data []byte, err error) {
+	defer recoverPanic(&err)
+	ap := p.newParam("", "")
+	if err := ap.checkInputSize(argumentsSize(signature, values)); err != nil {
+		return nil, err
+	}
+	method, fixed, err := parseSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+	return ap.encodeMethod(method, fixed, values)
+}
+
+// EncodeCallJSON 与 EncodeCall 相同，函数定义来自 JSON ABI 片段，eg:
+// {"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}]}
+// 片段也可以是只包含一个函数的 ABI 数组
+func EncodeCallJSON(fragment string, values ...string) ([]byte, error) {
+	return defaultParser.EncodeCallJSON(fragment, values...)
+}
+
+// EncodeCallJSON 按 p 的设置解析参数并编码 calldata，见 EncodeCallJSON
+func (p *Parser) EncodeCallJSON(fragment string, values ...string) (data []byte, err error) {
+	defer recoverPanic(&err)
+	ap := p.newParam("", "")
+	if err := ap.checkInputSize(argumentsSize(fragment, values)); err != nil {
+		return nil, err
+	}
+	method, err := loadMethod(fragment)
+	if err != nil {
+		return nil, err
+	}
+	return ap.encodeMethod(method, nil, values)
+}
+
+// encodeMethod 解析参数并编码 calldata，fixed 为参数中的定点数，见 parseSignature
+func (ap *AbiParam) encodeMethod(method abi.Method, fixed fixedTypes, values []string) ([]byte, error) {
+	if len(values) != len(method.Inputs) {
+		return nil, fmt.Errorf("%s want %d arguments, got %d", method.Sig, len(method.Inputs), len(values))
+	}
+	if err := ap.checkArgumentLimits(method.Inputs, fixed); err != nil {
+		return nil, err
+	}
+
+	ap.fixed = fixed
+	args, err := ap.parseArguments(method.Inputs, values)
+	if err != nil {
+		return nil, err
+	}
+
+	packed, err := method.Inputs.Pack(args...)
+	if err != nil {
+		return nil, err
+	}
+	return append(method.ID, packed...), nil
+}
+
+// parseSignature 解析形如 transfer(address,uint256) 的函数签名，参数写法与 newType 的 tuple 一致
+// 返回值可以写在参数之后：balanceOf(address)(uint256) 或 balanceOf(address) returns (uint256)
+// 第二个返回值为参数与返回值中的定点数，key 为 method.Inputs 与 method.Outputs 中类型的地址
+func parseSignature(signature string) (abi.Method, fixedTypes, error) {
+	signature = strings.TrimSpace(signature)
+	start := strings.Index(signature, "(")
+	if start <= 0 {
+		return abi.Method{}, nil, fmt.Errorf("invalid function signature %q", signature)
+	}
+	name := strings.TrimSpace(signature[:start])
+	end, err := matchParen(signature, start)
+	if err != nil {
+		return abi.Method{}, nil, fmt.Errorf("invalid function signature %q: %s", signature, err)
+	}
+
+	inputs, fixed, err := newArguments(signature[start : end+1])
+	if err != nil {
+		return abi.Method{}, nil, fmt.Errorf("invalid function signature %q: %s", signature, err)
+	}
+
+	var outputs abi.Arguments
+	rest := strings.TrimSpace(signature[end+1:])
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, "returns"))
+	if rest != "" {
+		if !strings.HasPrefix(rest, "(") || !strings.HasSuffix(rest, ")") {
+			return abi.Method{}, nil, fmt.Errorf("invalid function signature %q: unexpected %q", signature, rest)
+		}
+		var outFixed fixedTypes
+		if outputs, outFixed, err = newArguments(rest); err != nil {
+			return abi.Method{}, nil, fmt.Errorf("invalid function signature %q: %s", signature, err)
+		}
+		fixed = fixed.merge(outFixed)
+	}
+
+	method := abi.NewMethod(name, name, abi.Function, "nonpayable", false, false, inputs, outputs)
+	if len(fixed) > 0 {
+		// go-ethereum 按 intM 计算签名，这里按定点数的类型名重新计算，与 solidity 一致
+		types := make([]string, len(inputs))
+		for i := range inputs {
+			types[i] = fixed.typeName(&inputs[i].Type)
+		}
+		method.Sig = name + "(" + strings.Join(types, ",") + ")"
+		method.ID = crypto.Keccak256([]byte(method.Sig))[:4]
+	}
+	return method, fixed, nil
+}
+
+// newArguments 把 (address to,uint256) 形式的参数列表转换为 abi.Arguments，
+// 第二个返回值为其中的定点数，key 为返回的 abi.Arguments 中类型的地址
+func newArguments(blob string) (abi.Arguments, fixedTypes, error) {
+	if strings.TrimSpace(strings.Trim(blob, "()")) == "" {
+		return abi.Arguments{}, nil, nil
+	}
+	tuple, err := parseTypeMarshaling(strings.TrimSpace(blob), "", 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tuple.Type != "tuple" {
+		return nil, nil, fmt.Errorf("invalid argument list %q", blob)
+	}
+
+	// 参数本身可以没有名字，tuple 的成员需要
+	var fixed fixedTypes
+	args := make(abi.Arguments, len(tuple.Components))
+	for i, c := range tuple.Components {
+		args[i].Name = c.Name
+		f, _, err := buildType(&args[i].Type, c)
+		if err != nil {
+			return nil, nil, err
+		}
+		fixed = fixed.merge(f)
+	}
+	return args, fixed, nil
+}
+
+// DecodedParam 是解码后的一个参数，Value 的格式与 NewAbiParam 接受的一致
+type DecodedParam struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// DecodeCall 校验 selector 并解码 calldata，signatureOrABI 可以是函数签名，也可以是 JSON ABI。
+// JSON ABI 中有多个函数时按 selector 匹配
+func DecodeCall(signatureOrABI string, calldata []byte) ([]DecodedParam, error) {
+	return defaultParser.DecodeCall(signatureOrABI, calldata)
+}
+
+// DecodeCall 按 p 的资源限制解码 calldata，见 DecodeCall
+func (p *Parser) DecodeCall(signatureOrABI string, calldata []byte) (params []DecodedParam, err error) {
+	defer recoverPanic(&err)
+	if err := p.newParam("", "").checkInputSize(len(signatureOrABI) + len(calldata)); err != nil {
+		return nil, err
+	}
+	if len(calldata) < 4 {
+		return nil, fmt.Errorf("calldata too short: %d bytes", len(calldata))
+	}
+
+	var (
+		method abi.Method
+		fixed  fixedTypes
+	)
+	if isJSON(signatureOrABI) {
+		parsed, err := loadABI(signatureOrABI)
+		if err != nil {
+			return nil, err
+		}
+		m, err := parsed.MethodById(calldata[:4])
+		if err != nil {
+			return nil, err
+		}
+		method = *m
+	} else {
+		m, f, err := parseSignature(signatureOrABI)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(m.ID, calldata[:4]) {
+			return nil, fmt.Errorf("selector mismatch: %s is %s, calldata has %s", m.Sig, hexutil.Encode(m.ID), hexutil.Encode(calldata[:4]))
+		}
+		method, fixed = m, f
+	}
+	return decodeArguments(method.Inputs, fixed, calldata[4:])
+}
+
+// DecodeOutput 解码函数的返回值，signatureOrABI 为带返回值的函数签名，eg: balanceOf(address)(uint256)，
+// 或只包含一个函数的 JSON ABI 片段
+func DecodeOutput(signatureOrABI string, data []byte) ([]DecodedParam, error) {
+	return defaultParser.DecodeOutput(signatureOrABI, data)
+}
+
+// DecodeOutput 按 p 的资源限制解码函数的返回值，见 DecodeOutput
+func (p *Parser) DecodeOutput(signatureOrABI string, data []byte) (params []DecodedParam, err error) {
+	defer recoverPanic(&err)
+	if err := p.newParam("", "").checkInputSize(len(signatureOrABI) + len(data)); err != nil {
+		return nil, err
+	}
+	var (
+		method abi.Method
+		fixed  fixedTypes
+	)
+	if isJSON(signatureOrABI) {
+		method, err = loadMethod(signatureOrABI)
+	} else {
+		method, fixed, err = parseSignature(signatureOrABI)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeArguments(method.Outputs, fixed, data)
+}
+
+// decodeArguments 解码 data 并格式化每个参数，fixed 为参数中的定点数
+func decodeArguments(args abi.Arguments, fixed fixedTypes, data []byte) ([]DecodedParam, error) {
+	values, err := args.Unpack(data)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]DecodedParam, len(args))
+	for i, arg := range args {
+		value, err := format(fixed, &args[i].Type, values[i])
+		if err != nil {
+			return nil, fmt.Errorf("argument %d (%s): %s", i, arg.Name, err)
+		}
+		params[i] = DecodedParam{Name: arg.Name, Type: fixed.typeName(&args[i].Type), Value: value}
+	}
+	return params, nil
+}
+
+func isJSON(signatureOrABI string) bool {
+	s := strings.TrimSpace(signatureOrABI)
+	return strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[")
+}
+
+func loadABI(fragment string) (abi.ABI, error) {
+	fragment = strings.TrimSpace(fragment)
+	if strings.HasPrefix(fragment, "{") {
+		fragment = "[" + fragment + "]"
+	}
+	parsed, err := abi.JSON(strings.NewReader(fragment))
+	if err != nil {
+		return abi.ABI{}, err
+	}
+	for _, method := range parsed.Methods {
+		if err := checkArgumentsSize(method.Inputs); err != nil {
+			return abi.ABI{}, fmt.Errorf("%s: %s", method.Sig, err)
+		}
+		if err := checkArgumentsSize(method.Outputs); err != nil {
+			return abi.ABI{}, fmt.Errorf("%s: %s", method.Sig, err)
+		}
+	}
+	return parsed, nil
+}
+
+// loadMethod 从只包含一个函数的 JSON ABI 片段中读取函数定义
+func loadMethod(fragment string) (abi.Method, error) {
+	parsed, err := loadABI(fragment)
+	if err != nil {
+		return abi.Method{}, err
+	}
+	if len(parsed.Methods) != 1 {
+		return abi.Method{}, fmt.Errorf("abi fragment should define exactly one function, got %d", len(parsed.Methods))
+	}
+	for _, method := range parsed.Methods {
+		return method, nil
+	}
+	return abi.Method{}, nil
+}
@@ -0,0 +1,546 @@
+package go_abi_param
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/magiconair/properties/assert"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestEncodeCall(t *testing.T) {
+	tests := []struct {
+		name      string
+		signature string
+		values    []string
+		want      string
+	}{
+		{
+			name:      "normal: transfer",
+			signature: "transfer(address,uint256)",
+			values:    []string{"0x00000000006c3852cbef3e08e8df289169ede581", "1e18"},
+			want:      "0xa9059cbb00000000000000000000000000000000006c3852cbef3e08e8df289169ede5810000000000000000000000000000000000000000000000000de0b6b3a7640000",
+		},
+		{
+			name:      "normal: named arguments",
+			signature: "transfer(address to, uint256 amount)",
+			values:    []string{"0x00000000006c3852cbef3e08e8df289169ede581", "1e18"},
+			want:      "0xa9059cbb00000000000000000000000000000000006c3852cbef3e08e8df289169ede5810000000000000000000000000000000000000000000000000de0b6b3a7640000",
+		},
+		{
+			name:      "normal: no arguments",
+			signature: "totalSupply()",
+			want:      "0x18160ddd",
+		},
+		{
+			name:      "normal: function argument",
+			signature: "execute(function)",
+			values:    []string{"0x00000000006c3852cbef3e08e8df289169ede581.transfer(address,uint256)"},
+			want:      "0x10c8e92400000000006c3852cbef3e08e8df289169ede581a9059cbb0000000000000000",
+		},
+		{
+			name:      "normal: fixed point",
+			signature: "setRate(fixed a, (ufixed8x1 b, int8 c)[] d)",
+			values:    []string{"-1.5", "[(25.5,1)]"},
+			want: "0xda3c7fbc" +
+				"ffffffffffffffffffffffffffffffffffffffffffffffffeb2eedf284ea0000" +
+				"0000000000000000000000000000000000000000000000000000000000000040" +
+				"0000000000000000000000000000000000000000000000000000000000000001" +
+				"00000000000000000000000000000000000000000000000000000000000000ff" +
+				"0000000000000000000000000000000000000000000000000000000000000001",
+		},
+		{
+			name:      "normal: tuple",
+			signature: "exactInputSingle((address,address,uint24,address,uint256,uint256,uint256,uint160))",
+			values:    []string{"(0x00000000006c3852cbef3e08e8df289169ede581,0x1b2667862b2a4f46DfD6C53f561C58a8B0EED0D6,3000,0x00000000006c3852cbef3e08e8df289169ede581,1,2,3,0)"},
+			want: "0x414bf389" +
+				"00000000000000000000000000000000006c3852cbef3e08e8df289169ede581" +
+				"0000000000000000000000001b2667862b2a4f46dfd6c53f561c58a8b0eed0d6" +
+				"0000000000000000000000000000000000000000000000000000000000000bb8" +
+				"00000000000000000000000000000000006c3852cbef3e08e8df289169ede581" +
+				"0000000000000000000000000000000000000000000000000000000000000001" +
+				"0000000000000000000000000000000000000000000000000000000000000002" +
+				"0000000000000000000000000000000000000000000000000000000000000003" +
+				"0000000000000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			name:      "error: argument count",
+			signature: "transfer(address,uint256)",
+			values:    []string{"0x00000000006c3852cbef3e08e8df289169ede581"},
+		},
+		{
+			name:      "error: bad argument",
+			signature: "transfer(address,uint256)",
+			values:    []string{"0x00000000006c3852cbef3e08e8df289169ede581", "abc"},
+		},
+		{
+			name:      "error: bad signature",
+			signature: "transfer(address,uint256",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := EncodeCall(tt.signature, tt.values...)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("want error, got %x", data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("encode call error: %s", err)
+			}
+			assert.Equal(t, hexutil.Encode(data), tt.want)
+		})
+	}
+}
+
+func TestEncodeCallJSON(t *testing.T) {
+	want := "0xa9059cbb00000000000000000000000000000000006c3852cbef3e08e8df289169ede5810000000000000000000000000000000000000000000000000de0b6b3a7640000"
+	fragments := []string{
+		`{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}]}`,
+		`[{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}]}]`,
+	}
+	for _, fragment := range fragments {
+		data, err := EncodeCallJSON(fragment, "0x00000000006c3852cbef3e08e8df289169ede581", "1e18")
+		if err != nil {
+			t.Fatalf("encode call error: %s", err)
+		}
+		assert.Equal(t, hexutil.Encode(data), want)
+	}
+
+	_, err := EncodeCallJSON(`[{"type":"function","name":"a","inputs":[]},{"type":"function","name":"b","inputs":[]}]`)
+	if err == nil {
+		t.Errorf("want error for multiple functions")
+	}
+}
+
+func TestDecodeCall(t *testing.T) {
+	calldata := hexutil.MustDecode("0xa9059cbb00000000000000000000000000000000006c3852cbef3e08e8df289169ede5810000000000000000000000000000000000000000000000000de0b6b3a7640000")
+	want := []DecodedParam{
+		{Name: "to", Type: "address", Value: "0x00000000006c3852cbEf3e08E8dF289169EdE581"},
+		{Name: "amount", Type: "uint256", Value: "1000000000000000000"},
+	}
+
+	params, err := DecodeCall("transfer(address to,uint256 amount)", calldata)
+	if err != nil {
+		t.Fatalf("decode call error: %s", err)
+	}
+	assert.Equal(t, params, want)
+
+	erc20 := `[{"type":"function","name":"approve","inputs":[{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}]},
+		{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}]}]`
+	params, err = DecodeCall(erc20, calldata)
+	if err != nil {
+		t.Fatalf("decode call error: %s", err)
+	}
+	assert.Equal(t, params, want)
+
+	// 未命名的参数
+	params, err = DecodeCall("transfer(address,uint256)", calldata)
+	if err != nil {
+		t.Fatalf("decode call error: %s", err)
+	}
+	assert.Equal(t, params[0].Name, "")
+
+	if _, err := DecodeCall("approve(address,uint256)", calldata); err == nil {
+		t.Errorf("want selector mismatch error")
+	}
+	if _, err := DecodeCall("transfer(address,uint256)", calldata[:3]); err == nil {
+		t.Errorf("want short calldata error")
+	}
+}
+
+func TestDecodeCall_RoundTrip(t *testing.T) {
+	signature := "f((address,uint256)[],string[],bytes32,int8[2],fixed64x4)"
+	values := []string{
+		"[(0x00000000006c3852cbef3e08e8df289169ede581,1),(0x1b2667862b2a4f46DfD6C53f561C58a8B0EED0D6,2)]",
+		`["a,b",c]`,
+		"0x0000007b02230091a7ed01230072f7006a004d60a8d4e71d599b8104250f0000",
+		"[-1,2]",
+		"-3.1416",
+	}
+	calldata, err := EncodeCall(signature, values...)
+	if err != nil {
+		t.Fatalf("encode call error: %s", err)
+	}
+	params, err := DecodeCall(signature, calldata)
+	if err != nil {
+		t.Fatalf("decode call error: %s", err)
+	}
+
+	decoded := make([]string, len(params))
+	for i, param := range params {
+		decoded[i] = param.Value
+	}
+	again, err := EncodeCall(signature, decoded...)
+	if err != nil {
+		t.Fatalf("encode decoded values error: %s", err)
+	}
+	assert.Equal(t, again, calldata)
+	assert.Equal(t, params[0].Type, "(address,uint256)[]")
+	assert.Equal(t, params[4].Type, "fixed64x4")
+}
+
+func TestDecodeOutput(t *testing.T) {
+	data := hexutil.MustDecode("0x0000000000000000000000000000000000000000000000000de0b6b3a7640000")
+	want := []DecodedParam{{Type: "uint256", Value: "1000000000000000000"}}
+
+	for _, signature := range []string{"balanceOf(address)(uint256)", "balanceOf(address) returns (uint256)"} {
+		params, err := DecodeOutput(signature, data)
+		if err != nil {
+			t.Fatalf("decode output error: %s", err)
+		}
+		assert.Equal(t, params, want)
+	}
+
+	params, err := DecodeOutput(`{"type":"function","name":"balanceOf","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"balance","type":"uint256"}]}`, data)
+	if err != nil {
+		t.Fatalf("decode output error: %s", err)
+	}
+	assert.Equal(t, params, []DecodedParam{{Name: "balance", Type: "uint256", Value: "1000000000000000000"}})
+}
+
+func TestParseArguments(t *testing.T) {
+	addr := "0x00000000006c3852cbef3e08e8df289169ede581"
+	tests := []struct {
+		name   string
+		args   interface{}
+		values interface{}
+		want   string
+	}{
+		{
+			name:   "normal: values slice",
+			args:   "(address,uint256[],bool)",
+			values: []string{addr, "[1,2]", "true"},
+			want:   "[0x00000000006c3852cbEf3e08E8dF289169EdE581 [1 2] true]",
+		},
+		{
+			name:   "normal: single string",
+			args:   "(address,uint256[],bool)",
+			values: addr + ", [1,2], true",
+			want:   "[0x00000000006c3852cbEf3e08E8dF289169EdE581 [1 2] true]",
+		},
+		{
+			name:   "normal: single string with parentheses",
+			args:   "(address,uint256[],bool)",
+			values: "(" + addr + ",[1,2],true)",
+			want:   "[0x00000000006c3852cbEf3e08E8dF289169EdE581 [1 2] true]",
+		},
+		{
+			name:   "normal: single tuple argument",
+			args:   "((address,uint256))",
+			values: "(" + addr + ",1)",
+			want:   "[{0x00000000006c3852cbEf3e08E8dF289169EdE581 1}]",
+		},
+		{
+			name:   "normal: single tuple argument with one field",
+			args:   "((uint256))",
+			values: "(5)",
+			want:   "[{5}]",
+		},
+		{
+			name:   "normal: single tuple argument with one field in parentheses",
+			args:   "((uint256))",
+			values: "((5))",
+			want:   "[{5}]",
+		},
+		{
+			name:   "normal: single tuple argument with one array field",
+			args:   "((uint256[]))",
+			values: "([1,2])",
+			want:   "[{[1 2]}]",
+		},
+		{
+			name:   "normal: single tuple argument with one tuple field",
+			args:   "(((uint8,uint8)))",
+			values: "((1,2))",
+			want:   "[{{1 2}}]",
+		},
+		{
+			name:   "normal: abi.Arguments",
+			args:   mustArguments("(address to,uint256 amount)"),
+			values: []string{addr, "1e3"},
+			want:   "[0x00000000006c3852cbEf3e08E8dF289169EdE581 1000]",
+		},
+		{
+			name:   "normal: no arguments",
+			args:   "()",
+			values: []string{},
+			want:   "[]",
+		},
+		{
+			name:   "error: argument count",
+			args:   "(address,bool)",
+			values: []string{addr},
+		},
+		{
+			name:   "error: argument count in string",
+			args:   "(address,bool)",
+			values: addr,
+		},
+		{
+			name:   "error: values type",
+			args:   "(address,bool)",
+			values: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseArguments(tt.args, tt.values)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("want error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse arguments error: %s", err)
+			}
+			assert.Equal(t, fmt.Sprintf("%v", got), tt.want)
+
+			arguments, _, _ := toArguments(tt.args)
+			if _, err := arguments.Pack(got...); err != nil {
+				t.Errorf("pack error: %s", err)
+			}
+		})
+	}
+}
+
+func TestParseArguments_Errors(t *testing.T) {
+	_, err := ParseArguments("(address to,uint8 amount,bool ok)", []string{"0x01", "300", "maybe"})
+	var errs ArgumentErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("want ArgumentErrors, got %v", err)
+	}
+	assert.Equal(t, len(errs), 2)
+	assert.Equal(t, errs[0].Index, 1)
+	assert.Equal(t, errs[0].Name, "amount")
+	assert.Equal(t, errs[1].Index, 2)
+	assert.Equal(t, errs[1].Name, "ok")
+
+	var pe *ParseError
+	if !errors.As(errs[0], &pe) {
+		t.Fatalf("want *ParseError, got %v", errs[0])
+	}
+	assert.Equal(t, pe.Kind, ErrRange)
+
+	// 单个字符串时 offset 相对于整个字符串
+	_, err = ParseArguments("(uint8,bool[])", "1, [true, nope]")
+	if !errors.As(err, &pe) {
+		t.Fatalf("want *ParseError, got %v", err)
+	}
+	assert.Equal(t, pe.Path, "[1]")
+	assert.Equal(t, pe.Offset, 10)
+}
+
+func mustArguments(blob string) abi.Arguments {
+	args, _, err := newArguments(blob)
+	if err != nil {
+		panic(err)
+	}
+	return args
+}
+
+const namedTestABI = `[
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}]},
+	{"type":"function","name":"safeTransferFrom","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"}]},
+	{"type":"function","name":"safeTransferFrom","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"},{"name":"data","type":"bytes"}]},
+	{"type":"function","name":"exactInputSingle","inputs":[{"name":"params","type":"tuple","components":[
+		{"name":"tokenIn","type":"address"},{"name":"tokenOut","type":"address"},{"name":"fee","type":"uint24"},
+		{"name":"recipient","type":"address"},{"name":"amountIn","type":"uint256"},{"name":"amountOutMinimum","type":"uint256"},
+		{"name":"sqrtPriceLimitX96","type":"uint160"}]}]}
+]`
+
+// Parser 的设置同样作用于 calldata 的编码与解码
+func TestParser_Call(t *testing.T) {
+	addr := "0x00000000006c3852cbef3e08e8df289169ede581"
+	want, err := EncodeCall("transfer(address,uint256)", addr, "1500000")
+	if err != nil {
+		t.Fatalf("encode call error: %s", err)
+	}
+
+	p := NewParser(WithDecimals(6))
+	got, err := p.EncodeCall("transfer(address,uint256)", addr, "1.5")
+	if err != nil {
+		t.Fatalf("encode call error: %s", err)
+	}
+	assert.Equal(t, got, want)
+
+	fragment := `{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}]}`
+	got, err = p.EncodeCallJSON(fragment, addr, "1.5")
+	if err != nil {
+		t.Fatalf("encode call json error: %s", err)
+	}
+	assert.Equal(t, got, want)
+
+	contract, err := abi.JSON(strings.NewReader(namedTestABI))
+	if err != nil {
+		t.Fatalf("abi json error: %s", err)
+	}
+	_, got, err = p.EncodeNamedCall(contract, "transfer", map[string]string{"to": addr, "amount": "1.5"})
+	if err != nil {
+		t.Fatalf("encode named call error: %s", err)
+	}
+	assert.Equal(t, got, want)
+
+	zero := "0x0000000000000000000000000000000000000000"
+	if _, err := NewParser(WithStrict()).EncodeCall("transfer(address,uint256)", zero, "1"); err == nil {
+		t.Errorf("want zero address error in strict mode")
+	}
+
+	small := NewParser(WithLimits(Limits{MaxInputSize: 32}))
+	var pe *ParseError
+	if _, err := small.EncodeCall("transfer(address,uint256)", addr, "1"); !errors.As(err, &pe) || pe.Kind != ErrLimit {
+		t.Errorf("want input size error, got %v", err)
+	}
+	if _, err := small.DecodeCall("transfer(address,uint256)", want); !errors.As(err, &pe) || pe.Kind != ErrLimit {
+		t.Errorf("want input size error, got %v", err)
+	}
+}
+
+func TestEncodeNamedCall(t *testing.T) {
+	contract, err := abi.JSON(strings.NewReader(namedTestABI))
+	if err != nil {
+		t.Fatalf("abi json error: %s", err)
+	}
+	addr := "0x00000000006c3852cbef3e08e8df289169ede581"
+
+	args, data, err := EncodeNamedCall(contract, "transfer", map[string]string{"amount": "1e18", "to": addr})
+	if err != nil {
+		t.Fatalf("encode named call error: %s", err)
+	}
+	assert.Equal(t, fmt.Sprintf("%v", args), "[0x00000000006c3852cbEf3e08E8dF289169EdE581 1000000000000000000]")
+	assert.Equal(t, hexutil.Encode(data), "0xa9059cbb00000000000000000000000000000000006c3852cbef3e08e8df289169ede5810000000000000000000000000000000000000000000000000de0b6b3a7640000")
+
+	// 重载按参数名匹配
+	_, data, err = EncodeNamedCall(contract, "safeTransferFrom", map[string]string{"from": addr, "to": addr, "tokenId": "1", "data": "0x"})
+	if err != nil {
+		t.Fatalf("encode named call error: %s", err)
+	}
+	assert.Equal(t, hexutil.Encode(data[:4]), "0xb88d4fde")
+	_, data, err = EncodeNamedCall(contract, "safeTransferFrom", map[string]string{"from": addr, "to": addr, "tokenId": "1"})
+	if err != nil {
+		t.Fatalf("encode named call error: %s", err)
+	}
+	assert.Equal(t, hexutil.Encode(data[:4]), "0x42842e0e")
+
+	// tuple 按成员传入与整体传入结果一致
+	byField := map[string]string{
+		"params.tokenIn":           addr,
+		"params.tokenOut":          addr,
+		"params.fee":               "3000",
+		"params.recipient":         addr,
+		"params.amountIn":          "1",
+		"params.amountOutMinimum":  "2",
+		"params.sqrtPriceLimitX96": "0",
+	}
+	_, byFieldData, err := EncodeNamedCall(contract, "exactInputSingle", byField)
+	if err != nil {
+		t.Fatalf("encode named call error: %s", err)
+	}
+	_, wholeData, err := EncodeNamedCall(contract, "exactInputSingle", map[string]string{
+		"params": fmt.Sprintf("(%s,%s,3000,%s,1,2,0)", addr, addr, addr),
+	})
+	if err != nil {
+		t.Fatalf("encode named call error: %s", err)
+	}
+	assert.Equal(t, byFieldData, wholeData)
+	assert.Equal(t, hexutil.Encode(byFieldData[:4]), "0x04e45aaf")
+	// 参数名相同的重载按参数值能否解析区分
+	overloaded, err := abi.JSON(strings.NewReader(`[
+		{"type":"function","name":"f","inputs":[{"name":"x","type":"uint256"}]},
+		{"type":"function","name":"f","inputs":[{"name":"x","type":"bool"}]}
+	]`))
+	if err != nil {
+		t.Fatalf("abi json error: %s", err)
+	}
+	args, data, err = EncodeNamedCall(overloaded, "f", map[string]string{"x": "true"})
+	if err != nil {
+		t.Fatalf("encode named call error: %s", err)
+	}
+	assert.Equal(t, args, []interface{}{true})
+	assert.Equal(t, data[:4], overloaded.Methods["f0"].ID)
+	args, _, err = EncodeNamedCall(overloaded, "f", map[string]string{"x": "1.5 gwei"})
+	if err != nil {
+		t.Fatalf("encode named call error: %s", err)
+	}
+	assert.Equal(t, args, []interface{}{big.NewInt(1500000000)})
+	if _, _, err = EncodeNamedCall(overloaded, "f", map[string]string{"x": "1"}); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("want ambiguous error, got %v", err)
+	}
+}
+
+func TestEncodeNamedCall_Errors(t *testing.T) {
+	contract, err := abi.JSON(strings.NewReader(namedTestABI))
+	if err != nil {
+		t.Fatalf("abi json error: %s", err)
+	}
+	addr := "0x00000000006c3852cbef3e08e8df289169ede581"
+
+	_, _, err = EncodeNamedCall(contract, "transfer", map[string]string{"to": addr, "amout": "1", "memo": "x"})
+	var nErr *NamedArgumentError
+	if !errors.As(err, &nErr) {
+		t.Fatalf("want *NamedArgumentError, got %v", err)
+	}
+	assert.Equal(t, nErr.Missing, []string{"amount"})
+	assert.Equal(t, nErr.Extra, []string{"amout", "memo"})
+	assert.Equal(t, nErr.Suggest, map[string]string{"amout": "amount"})
+
+	_, _, err = EncodeNamedCall(contract, "exactInputSingle", map[string]string{"params.tokenIn": addr})
+	if !errors.As(err, &nErr) {
+		t.Fatalf("want *NamedArgumentError, got %v", err)
+	}
+	assert.Equal(t, len(nErr.Missing), 6)
+
+	_, _, err = EncodeNamedCall(contract, "transfer", map[string]string{"to": addr, "amount": "-1"})
+	var errs ArgumentErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("want ArgumentErrors, got %v", err)
+	}
+	assert.Equal(t, errs[0].Index, 1)
+	assert.Equal(t, errs[0].Name, "amount")
+
+	if _, _, err = EncodeNamedCall(contract, "approve", map[string]string{}); err == nil {
+		t.Errorf("want method not found error")
+	}
+}
+
+func TestParseArguments_JSON(t *testing.T) {
+	args := "(address to,uint256[] amounts,bool flag)"
+	want := []interface{}{
+		common.HexToAddress("0x00000000006c3852cbef3e08e8df289169ede581"),
+		[]*big.Int{big.NewInt(1000), big.NewInt(2000)},
+		true,
+	}
+
+	got, err := ParseArguments(args, json.RawMessage(`{"to":"0x00000000006c3852cbef3e08e8df289169ede581","amounts":["1000",2000],"flag":true}`))
+	if err != nil {
+		t.Fatalf("parse arguments error: %s", err)
+	}
+	assert.Equal(t, got, want)
+
+	got, err = ParseArguments(args, json.RawMessage(`["0x00000000006c3852cbef3e08e8df289169ede581",[1000,"2000"],true]`))
+	if err != nil {
+		t.Fatalf("parse arguments error: %s", err)
+	}
+	assert.Equal(t, got, want)
+
+	_, err = ParseArguments(args, json.RawMessage(`{"to":"0x00000000006c3852cbef3e08e8df289169ede581","amounts":["1000",2000],"flags":true}`))
+	if err == nil || !strings.Contains(err.Error(), "unknown argument flags") {
+		t.Errorf("want unknown argument error, got %v", err)
+	}
+
+	_, err = ParseArguments(args, json.RawMessage(`{"to":"0x00000000006c3852cbef3e08e8df289169ede581","amounts":["1000","abc"],"flag":2}`))
+	var errs ArgumentErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("want 2 argument errors, got %v", err)
+	}
+	assert.Equal(t, errs[0].Name, "amounts")
+	assert.Equal(t, errs[1].Name, "flag")
+}
@@ -0,0 +1,90 @@
+package go_abi_param
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"strings"
+)
+
+// ENSRegistry 主网及主要测试网的 ENS registry 地址
+var ENSRegistry = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+var (
+	ensRegistryABI = mustABI(`[{"type":"function","name":"resolver","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"name":"","type":"address"}]}]`)
+	ensResolverABI = mustABI(`[{"type":"function","name":"addr","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"name":"","type":"address"}]}]`)
+)
+
+func mustABI(fragment string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(fragment))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// ENSResolver 通过 ENS 解析名字，eg: vitalik.eth
+// caller 可以是 ethclient.Client，也可以是测试用的 backends.SimulatedBackend
+type ENSResolver struct {
+	caller   bind.ContractCaller
+	registry common.Address
+	opts     *bind.CallOpts
+}
+
+// NewENSResolver 创建 ENS 解析，registry 为 ENS registry 合约地址，一般为 ENSRegistry
+func NewENSResolver(caller bind.ContractCaller, registry common.Address) *ENSResolver {
+	return &ENSResolver{caller: caller, registry: registry}
+}
+
+// SetCallOpts 设置查询使用的 context 与区块高度
+func (r *ENSResolver) SetCallOpts(opts *bind.CallOpts) *ENSResolver {
+	r.opts = opts
+	return r
+}
+
+// Resolve 先从 registry 查询名字的 resolver，再从 resolver 查询地址，名字未注册或未设置地址时报错
+func (r *ENSResolver) Resolve(name string) (common.Address, error) {
+	node := namehash(name)
+
+	resolver, err := r.call(ensRegistryABI, r.registry, "resolver", node)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("ens registry: %s", err)
+	}
+	if resolver == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("ens name %s has no resolver", name)
+	}
+
+	addr, err := r.call(ensResolverABI, resolver, "addr", node)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("ens resolver: %s", err)
+	}
+	if addr == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("ens name %s has no address", name)
+	}
+	return addr, nil
+}
+
+func (r *ENSResolver) call(contractABI abi.ABI, contract common.Address, method string, node [32]byte) (common.Address, error) {
+	var out []interface{}
+	bound := bind.NewBoundContract(contract, contractABI, r.caller, nil, nil)
+	if err := bound.Call(r.opts, &out, method, node); err != nil {
+		return common.Address{}, err
+	}
+	return *abi.ConvertType(out[0], new(common.Address)).(*common.Address), nil
+}
+
+// namehash 按 EIP-137 计算名字的 node，名字统一转为小写
+func namehash(name string) [32]byte {
+	var node [32]byte
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		copy(node[:], crypto.Keccak256(node[:], crypto.Keccak256([]byte(labels[i]))))
+	}
+	return node
+}
@@ -0,0 +1,143 @@
+package go_abi_param
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrorKind 解析错误的类别
+type ErrorKind int
+
+const (
+	ErrSyntax   ErrorKind = iota + 1 // value 语法错误，eg: 括号不成对、字符串缺少引号
+	ErrType                          // blob 不是合法的 abi 类型
+	ErrMismatch                      // 值的结构与类型不符，eg: 需要数组却给了标量
+	ErrLength                        // 数组或 tuple 的成员个数不符，或 bytesN 的字节数不符
+	ErrInvalid                       // 标量无法转换为对应类型，eg: bool 写成 2
+	ErrRange                         // 数值超出类型范围或丢失精度
+	ErrLimit                         // 超出 Limits 中的资源限制
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrSyntax:
+		return "syntax error"
+	case ErrType:
+		return "invalid type"
+	case ErrMismatch:
+		return "type mismatch"
+	case ErrLength:
+		return "length mismatch"
+	case ErrInvalid:
+		return "invalid value"
+	case ErrRange:
+		return "value out of range"
+	case ErrLimit:
+		return "limit exceeded"
+	default:
+		return "unknown error"
+	}
+}
+
+// ParseError 描述解析失败的位置与原因，可以通过 errors.As 取出：
+//
+//	var pe *ParseError
+//	if errors.As(err, &pe) {
+//		// pe.Path: [1][0]，pe.Offset: 出错元素在 value 中的字节偏移
+//	}
+type ParseError struct {
+	Kind   ErrorKind
+	Type   string // 出错元素的 abi 类型，eg: bool
+	Path   string // 出错元素的路径，eg: [1][0]、.amount，最外层为空
+	Offset int    // 出错位置在 value 中的字节偏移
+	Token  string // 出错的原文
+	Err    error  // 具体原因
+}
+
+func (e *ParseError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("param: ")
+	sb.WriteString(e.Kind.String())
+	if e.Path != "" {
+		sb.WriteString(" at ")
+		sb.WriteString(e.Path)
+	}
+	if e.Type != "" {
+		fmt.Fprintf(&sb, " (%s)", e.Type)
+	}
+	fmt.Fprintf(&sb, " offset %d", e.Offset)
+	if e.Token != "" {
+		fmt.Fprintf(&sb, " near %q", e.Token)
+	}
+	if e.Err != nil {
+		sb.WriteString(": ")
+		sb.WriteString(e.Err.Error())
+	}
+	return sb.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func syntaxError(offset int, token string, format string, args ...interface{}) *ParseError {
+	return &ParseError{Kind: ErrSyntax, Offset: offset, Token: token, Err: fmt.Errorf(format, args...)}
+}
+
+func rangeError(format string, args ...interface{}) *ParseError {
+	return &ParseError{Kind: ErrRange, Err: fmt.Errorf(format, args...)}
+}
+
+func limitError(offset int, format string, args ...interface{}) *ParseError {
+	return &ParseError{Kind: ErrLimit, Offset: offset, Err: fmt.Errorf(format, args...)}
+}
+
+// errPanic 解析过程中出现的 panic，正常情况下不应出现，出现时说明存在 bug
+var errPanic = errors.New("internal error")
+
+// recoverPanic 在对外的入口处把 panic 转换为错误，使不可信的输入不会导致进程崩溃，用法：
+//
+//	defer recoverPanic(&err)
+func recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = &ParseError{Kind: ErrInvalid, Err: fmt.Errorf("%w: %v", errPanic, r)}
+	}
+}
+
+// inPath 在 err 的路径前补上出错元素在外层中的位置，eg: [1] + .amount => [1].amount，err 为空时返回 nil
+// 路径只在出错时拼接，正常解析不需要为每个元素生成路径；语法错误只记录偏移，不补路径
+func inPath(err error, elem string) error {
+	var pe *ParseError
+	if errors.As(err, &pe) && pe.Kind != ErrSyntax {
+		pe.Path = elem + pe.Path
+	}
+	return err
+}
+
+// indexPath 返回数组元素的路径，eg: [3]
+func indexPath(i int) string {
+	return "[" + strconv.Itoa(i) + "]"
+}
+
+// withPosition 为标量读取函数返回的错误补上类型与位置，未分类的错误视为 ErrInvalid
+func withPosition(err error, typ string, path string, offset int, token string) error {
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		pe = &ParseError{Kind: ErrInvalid, Err: err}
+	}
+	if pe.Type == "" {
+		pe.Type = typ
+	}
+	if pe.Path == "" {
+		pe.Path = path
+	}
+	if pe.Offset == 0 {
+		pe.Offset = offset
+	}
+	if pe.Token == "" {
+		pe.Token = token
+	}
+	return pe
+}
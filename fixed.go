@@ -0,0 +1,175 @@
+package go_abi_param
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// fixedMxN / ufixedMxN 定点数：值 v 按 intM / uintM 编码 v * 10^N，eg: fixed128x18 的 1.5 => 1500000000000000000
+// go-ethereum 的 abi.NewType 不支持定点数，这里按 intM / uintM 构造类型，小数位数记录在 fixedTypes 中，
+// 这样 abi.Arguments 可以直接 Pack/Unpack，函数签名与 selector 按 fixedTypes.typeName 计算，与 solidity 一致
+// 省略 MxN 时 fixed / ufixed 等价于 fixed128x18 / ufixed128x18
+
+var fixedRegex = regexp.MustCompile(`^(u?)fixed((\d+)x(\d+))?`)
+
+// fixedTypes 记录类型树中的定点数，key 为按 intM / uintM 构造的节点的地址，value 为小数位数 N，
+// 类型中没有定点数时为 nil
+type fixedTypes map[*abi.Type]int
+
+// fixedToInteger 把类型名开头的 fixedMxN 替换为 intM，eg: ufixed64x10[2] => uint64[2]
+// 第二个返回值表示是否为定点数
+func fixedToInteger(t string) (string, bool, error) {
+	m := fixedRegex.FindStringSubmatch(t)
+	if m == nil {
+		return t, false, nil
+	}
+	size, decimals := 128, 18
+	if m[2] != "" {
+		size, _ = strconv.Atoi(m[3])
+		decimals, _ = strconv.Atoi(m[4])
+	}
+	// 类型名之后只能是数组维度，eg: fixed12 不是合法的定点数
+	suffix := t[len(m[0]):]
+	if size < 8 || size > 256 || size%8 != 0 || decimals > 80 || (suffix != "" && suffix[0] != '[') {
+		return "", false, fmt.Errorf("unsupported arg type: %s", t)
+	}
+	return fmt.Sprintf("%sint%d", m[1], size) + suffix, true, nil
+}
+
+// newTypeWithFixed 与 abi.NewType 相同，但支持 fixedMxN / ufixedMxN，类型写入 typ，
+// 返回其中的定点数，key 包含 typ 本身的地址，因此 typ 之后不能再复制到别处使用
+func newTypeWithFixed(typ *abi.Type, t string, internalType string, components []abi.ArgumentMarshaling) (fixedTypes, error) {
+	rewritten, hasFixed, err := rewriteFixed(t, components)
+	if err != nil {
+		return nil, err
+	}
+	if !hasFixed {
+		*typ, err = abi.NewType(t, internalType, components)
+		return nil, err
+	}
+	if *typ, err = abi.NewType(rewritten.Type, internalType, rewritten.Components); err != nil {
+		return nil, err
+	}
+	fixed := fixedTypes{}
+	fixed.collect(typ, t, components)
+	return fixed, nil
+}
+
+// rewriteFixed 返回把所有定点数替换为整数后的类型，不修改 components
+func rewriteFixed(t string, components []abi.ArgumentMarshaling) (abi.ArgumentMarshaling, bool, error) {
+	rewritten, hasFixed, err := fixedToInteger(t)
+	if err != nil {
+		return abi.ArgumentMarshaling{}, false, err
+	}
+	ret := abi.ArgumentMarshaling{Type: rewritten}
+	for _, c := range components {
+		comp, fixed, err := rewriteFixed(c.Type, c.Components)
+		if err != nil {
+			return abi.ArgumentMarshaling{}, false, err
+		}
+		comp.Name, comp.InternalType = c.Name, c.InternalType
+		ret.Components = append(ret.Components, comp)
+		hasFixed = hasFixed || fixed
+	}
+	return ret, hasFixed, nil
+}
+
+// collect 按原始类型名找出 typ 及其成员中的定点数
+func (f fixedTypes) collect(typ *abi.Type, t string, components []abi.ArgumentMarshaling) {
+	switch typ.T {
+	case abi.SliceTy, abi.ArrayTy:
+		f.collect(typ.Elem, t[:strings.LastIndex(t, "[")], components)
+	case abi.TupleTy:
+		for i, elem := range typ.TupleElems {
+			f.collect(elem, components[i].Type, components[i].Components)
+		}
+	case abi.IntTy, abi.UintTy:
+		if m := fixedRegex.FindStringSubmatch(t); m != nil {
+			decimals := 18
+			if m[2] != "" {
+				decimals, _ = strconv.Atoi(m[4])
+			}
+			f[typ] = decimals
+		}
+	}
+}
+
+// typeName 返回 typ 的类型名，其中的定点数按 fixedMxN 书写，eg: (int128,uint8)[] => (fixed128x18,ufixed8x1)[]
+func (f fixedTypes) typeName(typ *abi.Type) string {
+	if len(f) == 0 {
+		return typ.String()
+	}
+	switch typ.T {
+	case abi.SliceTy:
+		return f.typeName(typ.Elem) + "[]"
+	case abi.ArrayTy:
+		return f.typeName(typ.Elem) + "[" + strconv.Itoa(typ.Size) + "]"
+	case abi.TupleTy:
+		names := make([]string, len(typ.TupleElems))
+		for i, elem := range typ.TupleElems {
+			names[i] = f.typeName(elem)
+		}
+		return "(" + strings.Join(names, ",") + ")"
+	case abi.IntTy, abi.UintTy:
+		if decimals, ok := f[typ]; ok {
+			prefix := "fixed"
+			if typ.T == abi.UintTy {
+				prefix = "ufixed"
+			}
+			return fmt.Sprintf("%s%dx%d", prefix, typ.Size, decimals)
+		}
+	}
+	return typ.String()
+}
+
+// merge 把 other 中的定点数合并到 f，返回合并后的结果
+func (f fixedTypes) merge(other fixedTypes) fixedTypes {
+	if len(other) == 0 {
+		return f
+	}
+	if f == nil {
+		f = fixedTypes{}
+	}
+	for typ, decimals := range other {
+		f[typ] = decimals
+	}
+	return f
+}
+
+// readFixed 把十进制数转换为定点数的整数表示，超出范围或小数位超过 N 时报错，name 为定点数的类型名
+func readFixed(typ abi.Type, name string, value string, decimals int) (interface{}, error) {
+	bv, err := parseDecimal(value, decimals)
+	if err != nil {
+		return nil, err
+	}
+	min, max := integerBounds(typ.T == abi.IntTy, typ.Size)
+	if bv.Cmp(max) > 0 {
+		return nil, rangeError("abi: value %s overflows %s, max is %s", value, name, formatFixed(max.String(), decimals))
+	}
+	if bv.Cmp(min) < 0 {
+		return nil, rangeError("abi: value %s underflows %s, min is %s", value, name, formatFixed(min.String(), decimals))
+	}
+	return toInteger(typ, bv), nil
+}
+
+// formatFixed 把定点数的整数表示格式化为十进制数，eg: 1500000000000000000, 18 => 1.5
+func formatFixed(s string, decimals int) string {
+	if decimals == 0 {
+		return s
+	}
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	if len(s) <= decimals {
+		s = strings.Repeat("0", decimals-len(s)+1) + s
+	}
+	intPart, fracPart := s[:len(s)-decimals], strings.TrimRight(s[len(s)-decimals:], "0")
+	if fracPart == "" {
+		return sign + intPart
+	}
+	return sign + intPart + "." + fracPart
+}
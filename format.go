@@ -0,0 +1,208 @@
+package go_abi_param
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Format 是 Parse 的逆过程：把 abi.Arguments.Unpack 得到的 go 值格式化为 NewAbiParam 可以解析的字符串
+// eg: [][2]bool{{true,false}} => [[true,false]]，*big.Int => 十进制，[]byte/[32]byte => 0x...
+// 任何输入都不会 panic，格式化过程中出现的 panic 以错误返回
+func Format(typ abi.Type, value interface{}) (string, error) {
+	return format(nil, &typ, value)
+}
+
+// format 与 Format 相同，fixed 中的整数按定点数格式化，见 fixedTypes
+func format(fixed fixedTypes, typ *abi.Type, value interface{}) (s string, err error) {
+	defer recoverPanic(&err)
+	var sb strings.Builder
+	if err := formatValue(&sb, fixed, typ, reflect.ValueOf(value), false); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// formatValue 把 v 写入 sb，nested 表示 v 位于数组或 tuple 内部，字符串需要按语法加引号
+func formatValue(sb *strings.Builder, fixed fixedTypes, typ *abi.Type, v reflect.Value, nested bool) error {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return fmt.Errorf("abi: cannot format nil as %s", fixed.typeName(typ))
+	}
+
+	switch typ.T {
+	case abi.SliceTy, abi.ArrayTy:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return fmt.Errorf("abi: cannot format %s as %s", v.Type(), fixed.typeName(typ))
+		}
+		if typ.T == abi.ArrayTy && v.Len() != typ.Size {
+			return fmt.Errorf("abi: cannot format %s as %s: want %d elements, got %d", v.Type(), fixed.typeName(typ), typ.Size, v.Len())
+		}
+		sb.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			if err := formatValue(sb, fixed, typ.Elem, v.Index(i), true); err != nil {
+				return err
+			}
+		}
+		sb.WriteByte(']')
+		return nil
+	case abi.TupleTy:
+		if v.Kind() == reflect.Ptr {
+			if v = v.Elem(); !v.IsValid() {
+				return fmt.Errorf("abi: cannot format nil as %s", fixed.typeName(typ))
+			}
+		}
+		if v.Kind() != reflect.Struct || v.NumField() != len(typ.TupleElems) {
+			return fmt.Errorf("abi: cannot format %s as %s", v.Type(), fixed.typeName(typ))
+		}
+		sb.WriteByte('(')
+		for i, elem := range typ.TupleElems {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			if err := formatValue(sb, fixed, elem, v.Field(i), true); err != nil {
+				return err
+			}
+		}
+		sb.WriteByte(')')
+		return nil
+	}
+
+	if !v.CanInterface() {
+		return fmt.Errorf("abi: cannot format unexported field as %s", fixed.typeName(typ))
+	}
+	s, err := formatScalar(fixed, typ, v)
+	if err != nil {
+		return err
+	}
+	// 最外层的空字符串也要加引号，NewAbiParam 不接受空的 value
+	if typ.T == abi.StringTy && (nested || s == "" || strings.HasPrefix(s, "\"")) {
+		s = quoteString(s)
+	}
+	sb.WriteString(s)
+	return nil
+}
+
+func formatScalar(fixed fixedTypes, typ *abi.Type, v reflect.Value) (string, error) {
+	switch typ.T {
+	case abi.IntTy, abi.UintTy:
+		s, err := formatInteger(*typ, v)
+		if decimals, ok := fixed[typ]; ok && err == nil {
+			s = formatFixed(s, decimals)
+		}
+		return s, err
+	case abi.BoolTy:
+		if v.Kind() == reflect.Bool {
+			return strconv.FormatBool(v.Bool()), nil
+		}
+	case abi.StringTy:
+		if v.Kind() == reflect.String {
+			return v.String(), nil
+		}
+	case abi.AddressTy:
+		if addr, ok := v.Interface().(common.Address); ok {
+			return addr.Hex(), nil
+		}
+	case abi.HashTy:
+		if hash, ok := v.Interface().(common.Hash); ok {
+			return hash.Hex(), nil
+		}
+	case abi.BytesTy, abi.FixedBytesTy:
+		if b, ok := bytesOf(v); ok {
+			return hexutil.Encode(b), nil
+		}
+	case abi.FunctionTy:
+		// 格式化为 address:selector，eg: 0x00000000006c3852cbEf3e08E8dF289169EdE581:0xa9059cbb
+		if b, ok := bytesOf(v); ok && len(b) == 24 {
+			return common.BytesToAddress(b[:20]).Hex() + ":" + hexutil.Encode(b[20:]), nil
+		}
+	default:
+		return "", fmt.Errorf("abi: unknown type %v", typ.T)
+	}
+	return "", fmt.Errorf("abi: cannot format %s as %s", v.Type(), fixed.typeName(typ))
+}
+
+func formatInteger(typ abi.Type, v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	}
+	switch bi := v.Interface().(type) {
+	case *big.Int:
+		if bi == nil {
+			return "", fmt.Errorf("abi: cannot format nil as %s", typ.String())
+		}
+		return bi.String(), nil
+	case big.Int:
+		return bi.String(), nil
+	}
+	return "", fmt.Errorf("abi: cannot format %s as %s", v.Type(), typ.String())
+}
+
+// bytesOf 取出 []byte 或 [N]byte 的内容
+func bytesOf(v reflect.Value) ([]byte, bool) {
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Type().Elem().Kind() != reflect.Uint8 {
+		return nil, false
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		return v.Bytes(), true
+	case reflect.Array:
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return b, true
+	}
+	return nil, false
+}
+
+// quoteString 数组或 tuple 中的字符串含有分隔符、空格、控制字符或为空时需要加引号，
+// 最外层的字符串按原文解析，只有以引号开头或为空时需要加引号
+func quoteString(s string) string {
+	if s != "" && !strings.ContainsAny(s, "[](),\"\\ ") && !hasControl(s) {
+		return s
+	}
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				fmt.Fprintf(&sb, `\u%04x`, c)
+			} else {
+				sb.WriteByte(c)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+func hasControl(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] == 0x7f {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package go_abi_param
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"math/big"
+	"reflect"
+)
+
+// ParseInto 解析 value 并写入 dst，dst 必须是指针，可以指向：
+// 1. 自定义结构体，tuple 成员按 `abi:"name"` tag 匹配，没有 tag 时按 abi.ToCamelCase(name) 匹配字段名
+// 2. 类型化的 slice/array，eg: *[2][]bool、*[]uint64
+// 3. 可以容纳对应值的标量，eg: uint8 可以写入 *uint64，*big.Int 可以写入 *int64（不溢出时）
+// 转换失败时返回 *ParseError，Path 指向出错的字段
+func (ap *AbiParam) ParseInto(dst interface{}) (err error) {
+	defer recoverPanic(&err)
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("param: ParseInto needs a non-nil pointer, got %T", dst)
+	}
+	if err := ap.checkInputSize(len(ap.blob) + len(ap.value)); err != nil {
+		return err
+	}
+
+	typ, err := ap.typeOf(ap.blob)
+	if err != nil {
+		return &ParseError{Kind: ErrType, Type: ap.blob, Err: fmt.Errorf("blob to go type error: %s", err)}
+	}
+	if err := ap.checkTypeLimits(typ); err != nil {
+		return err
+	}
+	ap.fixed = typ.fixed
+	parsed, err := ap.parseValue(&typ.Type)
+	if err != nil {
+		return err
+	}
+	return assign(typ.Type, rv.Elem(), reflect.ValueOf(parsed), "")
+}
+
+// ParseAs 解析 value 并返回 T 类型的值，eg:
+//
+//	amounts, err := ParseAs[[]uint64]("uint256[]", "[1,2,3]")
+func ParseAs[T any](blob, value string) (T, error) {
+	var ret T
+	ap, err := NewAbiParam(blob, value)
+	if err != nil {
+		return ret, err
+	}
+	err = ap.ParseInto(&ret)
+	return ret, err
+}
+
+var bigIntType = reflect.TypeOf((*big.Int)(nil))
+
+// assign 把解析结果 src 写入 dst，typ 为 src 对应的 abi 类型
+func assign(typ abi.Type, dst reflect.Value, src reflect.Value, path string) error {
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+	// *big.Int 由 assignInteger 处理，其它指针先分配再写入指向的值
+	if dst.Kind() == reflect.Ptr && dst.Type() != bigIntType {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return assign(typ, dst.Elem(), src, path)
+	}
+
+	mismatch := func(format string, args ...interface{}) error {
+		return &ParseError{Kind: ErrMismatch, Type: typ.String(), Path: path, Err: fmt.Errorf(format, args...)}
+	}
+
+	switch typ.T {
+	case abi.SliceTy, abi.ArrayTy:
+		switch dst.Kind() {
+		case reflect.Slice:
+			dst.Set(reflect.MakeSlice(dst.Type(), src.Len(), src.Len()))
+		case reflect.Array:
+			if dst.Len() != src.Len() {
+				return mismatch("cannot assign %d elements to %s", src.Len(), dst.Type())
+			}
+		default:
+			return mismatch("cannot assign %s to %s", src.Type(), dst.Type())
+		}
+		for i := 0; i < src.Len(); i++ {
+			if err := assign(*typ.Elem, dst.Index(i), src.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case abi.TupleTy:
+		if dst.Kind() != reflect.Struct {
+			return mismatch("cannot assign tuple to %s", dst.Type())
+		}
+		for i, name := range typ.TupleRawNames {
+			field, ok := structField(dst, name)
+			if !ok {
+				return mismatch("%s has no field for tuple member %s", dst.Type(), name)
+			}
+			if err := assign(*typ.TupleElems[i], field, src.Field(i), path+"."+name); err != nil {
+				return err
+			}
+		}
+		return nil
+	case abi.IntTy, abi.UintTy:
+		return assignInteger(typ, dst, src, path)
+	}
+
+	// address、bytesN 等定长数组可以在同长度的数组类型之间转换
+	if src.Kind() == reflect.Array && dst.Kind() == reflect.Array && src.Type().ConvertibleTo(dst.Type()) {
+		dst.Set(src.Convert(dst.Type()))
+		return nil
+	}
+	if src.Kind() == dst.Kind() && (src.Kind() == reflect.String || src.Kind() == reflect.Bool || src.Kind() == reflect.Slice) && src.Type().ConvertibleTo(dst.Type()) {
+		dst.Set(src.Convert(dst.Type()))
+		return nil
+	}
+	return mismatch("cannot assign %s to %s", src.Type(), dst.Type())
+}
+
+// structField 按 `abi:"name"` tag 或 abi.ToCamelCase(name) 查找结构体字段
+func structField(dst reflect.Value, name string) (reflect.Value, bool) {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if tag, ok := t.Field(i).Tag.Lookup("abi"); ok && tag == name {
+			return dst.Field(i), dst.Field(i).CanSet()
+		}
+	}
+	f, ok := t.FieldByName(abi.ToCamelCase(name))
+	if !ok {
+		return reflect.Value{}, false
+	}
+	field := dst.FieldByIndex(f.Index)
+	return field, field.CanSet()
+}
+
+// assignInteger 在整数类型之间转换，超出目标类型范围时报错
+func assignInteger(typ abi.Type, dst reflect.Value, src reflect.Value, path string) error {
+	var v *big.Int
+	switch src.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v = big.NewInt(src.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v = new(big.Int).SetUint64(src.Uint())
+	default:
+		bi, ok := src.Interface().(*big.Int)
+		if !ok {
+			return &ParseError{Kind: ErrMismatch, Type: typ.String(), Path: path, Err: fmt.Errorf("cannot assign %s to %s", src.Type(), dst.Type())}
+		}
+		v = bi
+	}
+
+	outOfRange := func() error {
+		return &ParseError{Kind: ErrRange, Type: typ.String(), Path: path, Err: fmt.Errorf("value %s overflows %s", v, dst.Type())}
+	}
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !v.IsInt64() || dst.OverflowInt(v.Int64()) {
+			return outOfRange()
+		}
+		dst.SetInt(v.Int64())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !v.IsUint64() || dst.OverflowUint(v.Uint64()) {
+			return outOfRange()
+		}
+		dst.SetUint(v.Uint64())
+	default:
+		switch dst.Interface().(type) {
+		case *big.Int:
+			dst.Set(reflect.ValueOf(new(big.Int).Set(v)))
+		case big.Int:
+			dst.Set(reflect.ValueOf(*new(big.Int).Set(v)))
+		default:
+			return &ParseError{Kind: ErrMismatch, Type: typ.String(), Path: path, Err: fmt.Errorf("cannot assign %s to %s", src.Type(), dst.Type())}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package go_abi_param
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// JSON 输入模式：value 为 JSON，按 abi.Type 转换为与 value 字符串相同的语法树后解析
+// 数组 => [1,2]，tuple => {"to":"0xabc","amount":"1000"} 或 ["0xabc","1000"]
+// 数字按 json.Number 原样保留，不会经过 float64 丢失精度，eg: 1000000000000000000000
+
+// NewAbiParamJSON 与 NewAbiParam 相同，但 value 为 JSON，eg:
+// NewAbiParamJSON("(address to,uint256[] amounts)", `{"to":"0xabc...","amounts":["1000",2000]}`)
+func NewAbiParamJSON(blob string, value string) (*AbiParam, error) {
+	ap := defaultParser.newParam(blob, value)
+	ap.json = true
+	return ap, ap.check()
+}
+
+// parseJSON 按已解析好的 abi.Type 解析 JSON value
+func (ap *AbiParam) parseJSON(typ *abi.Type, value string) (interface{}, error) {
+	r := newJSONReader(value, ap.limits, ap.fixed)
+	n, err := r.readNode(typ)
+	if err != nil {
+		return nil, withPosition(err, ap.fixed.typeName(typ), "", 0, "")
+	}
+	if err := r.end(); err != nil {
+		return nil, err
+	}
+	return ap.parseNode(typ, n)
+}
+
+// parseArgumentJSON 解析 JSON 形式的参数列表，对象按参数名匹配，未命名的参数用位置作为 key，
+// 数组按位置匹配，eg: {"to":"0xabc","amounts":["1000","2000"],"flag":true}
+func (ap *AbiParam) parseArgumentJSON(arguments abi.Arguments, value string) ([]interface{}, error) {
+	names := make([]string, len(arguments))
+	for i, arg := range arguments {
+		names[i] = argumentKey(arg, i)
+	}
+
+	r := newJSONReader(value, ap.limits, ap.fixed)
+	tok, pos, err := r.token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil, &ParseError{Kind: ErrMismatch, Offset: pos, Err: fmt.Errorf("expected JSON array or object of arguments, got %v", tok)}
+	}
+	nodes := make([]*node, len(arguments))
+	err = r.readFields(delim, names, "argument", func(i int) error {
+		n, err := r.readNode(&arguments[i].Type)
+		if err != nil {
+			return &ArgumentError{Index: i, Name: arguments[i].Name, Err: err}
+		}
+		nodes[i] = n
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := r.end(); err != nil {
+		return nil, err
+	}
+
+	var errs ArgumentErrors
+	ret := make([]interface{}, len(arguments))
+	for i, arg := range arguments {
+		v, err := ap.parseNode(&arguments[i].Type, nodes[i])
+		if err != nil {
+			errs = append(errs, &ArgumentError{Index: i, Name: arg.Name, Err: err})
+			continue
+		}
+		ret[i] = v
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return ret, nil
+}
+
+type jsonReader struct {
+	data   string
+	dec    *json.Decoder
+	depth  int
+	limits Limits
+	fixed  fixedTypes
+}
+
+func newJSONReader(value string, limits Limits, fixed fixedTypes) *jsonReader {
+	dec := json.NewDecoder(strings.NewReader(value))
+	dec.UseNumber()
+	return &jsonReader{data: value, dec: dec, limits: limits, fixed: fixed}
+}
+
+// offset 返回下一个 token 在 value 中的字节偏移
+func (r *jsonReader) offset() int {
+	i := int(r.dec.InputOffset())
+	for i < len(r.data) && (isSpace(r.data[i]) || r.data[i] == ',' || r.data[i] == ':') {
+		i++
+	}
+	return i
+}
+
+func (r *jsonReader) token() (json.Token, int, error) {
+	pos := r.offset()
+	tok, err := r.dec.Token()
+	if err != nil {
+		var se *json.SyntaxError
+		if errors.As(err, &se) {
+			return nil, pos, syntaxError(int(se.Offset), "", "%s", se)
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, pos, syntaxError(pos, "", "%s", err)
+	}
+	return tok, pos, nil
+}
+
+// end 确认 JSON 值之后没有多余的内容
+func (r *jsonReader) end() error {
+	pos := r.offset()
+	if _, err := r.dec.Token(); err != io.EOF {
+		return syntaxError(pos, r.data[pos:], "unexpected data after JSON value")
+	}
+	return nil
+}
+
+// readNode 读取一个 JSON 值并转换为 typ 对应的语法树节点
+func (r *jsonReader) readNode(typ *abi.Type) (*node, error) {
+	tok, pos, err := r.token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		return r.readGroup(typ, t, pos)
+	case json.Number:
+		return &node{kind: nodeScalar, text: t.String(), pos: pos}, nil
+	case bool:
+		return &node{kind: nodeScalar, text: strconv.FormatBool(t), pos: pos}, nil
+	case string:
+		return &node{kind: nodeString, text: t, pos: pos}, nil
+	default:
+		return nil, &ParseError{Kind: ErrInvalid, Type: r.fixed.typeName(typ), Offset: pos, Token: "null", Err: fmt.Errorf("null is not a valid %s", r.fixed.typeName(typ))}
+	}
+}
+
+// readGroup 读取 [ 或 { 之后的内容，数组与 tuple 都可以写成 JSON 数组，tuple 还可以写成对象
+func (r *jsonReader) readGroup(typ *abi.Type, delim json.Delim, pos int) (*node, error) {
+	mismatch := func(got string) error {
+		return &ParseError{Kind: ErrMismatch, Type: r.fixed.typeName(typ), Offset: pos, Err: fmt.Errorf("expected %s, got %s", r.fixed.typeName(typ), got)}
+	}
+	r.depth++
+	defer func() { r.depth-- }()
+	if max := r.limits.maxDepth(); r.depth > max {
+		return nil, limitError(pos, "nesting deeper than %d", max)
+	}
+
+	switch typ.T {
+	case abi.SliceTy, abi.ArrayTy:
+		if delim != '[' {
+			return nil, mismatch("object")
+		}
+		n := &node{kind: nodeList, pos: pos}
+		for i := 0; r.dec.More(); i++ {
+			if max := r.limits.MaxArrayLength; max > 0 && i >= max {
+				return nil, limitError(r.offset(), "more than %d elements", max)
+			}
+			elem, err := r.readNode(typ.Elem)
+			if err != nil {
+				return nil, inPath(err, indexPath(i))
+			}
+			n.elems = append(n.elems, elem)
+		}
+		_, _, err := r.token()
+		return n, err
+	case abi.TupleTy:
+		n := &node{kind: nodeTuple, pos: pos, elems: make([]*node, len(typ.TupleElems))}
+		err := r.readFields(delim, typ.TupleRawNames, "field", func(i int) error {
+			elem, err := r.readNode(typ.TupleElems[i])
+			n.elems[i] = elem
+			return inPath(err, "."+typ.TupleRawNames[i])
+		})
+		return n, err
+	default:
+		if delim == '[' {
+			return nil, mismatch("array")
+		}
+		return nil, mismatch("object")
+	}
+}
+
+// readFields 读取 [ 或 { 之后按位置或按名字给出的一组值，用于 tuple 与参数列表，
+// read 读取第 i 个值
+func (r *jsonReader) readFields(delim json.Delim, names []string, what string, read func(i int) error) error {
+	seen := make([]bool, len(names))
+	for i := 0; r.dec.More(); i++ {
+		if delim == '{' {
+			tok, pos, err := r.token()
+			if err != nil {
+				return err
+			}
+			key := tok.(string)
+			if i = indexOf(names, key); i < 0 {
+				return &ParseError{Kind: ErrMismatch, Offset: pos, Token: key, Err: fmt.Errorf("unknown %s %s", what, key)}
+			}
+			if seen[i] {
+				return &ParseError{Kind: ErrMismatch, Offset: pos, Token: key, Err: fmt.Errorf("duplicate %s %s", what, key)}
+			}
+		} else if i >= len(names) {
+			return &ParseError{Kind: ErrLength, Offset: r.offset(), Err: fmt.Errorf("want %d %ss, got more", len(names), what)}
+		}
+		seen[i] = true
+		if err := read(i); err != nil {
+			return err
+		}
+	}
+
+	var missing []string
+	for i, ok := range seen {
+		if !ok {
+			missing = append(missing, names[i])
+		}
+	}
+	if len(missing) > 0 {
+		return &ParseError{Kind: ErrLength, Offset: r.offset(), Err: fmt.Errorf("missing %s %s", what, strings.Join(missing, ", "))}
+	}
+	_, _, err := r.token()
+	return err
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
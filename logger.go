@@ -0,0 +1,61 @@
+package go_abi_param
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+// Logger 调试日志接口，通过 WithLogger 设置，默认不输出
+// *logrus.Logger 与 *logrus.Entry 直接实现了该接口，slog 可以使用 SlogLogger
+type Logger interface {
+	Debugf(format string, args ...interface{})
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debugf(string, ...interface{}) {}
+
+// logging 是否设置了 Logger，调用 Debugf 前先检查，避免默认情况下每次都为参数分配内存
+func (c *config) logging() bool {
+	_, nop := c.logger.(nopLogger)
+	return !nop
+}
+
+// SlogLogger 把 *slog.Logger 适配为 Logger，日志级别为 Debug
+func SlogLogger(logger *slog.Logger) Logger {
+	return slogLogger{logger}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l slogLogger) Debugf(format string, args ...interface{}) {
+	// 未开启 Debug 时不格式化消息
+	if !l.logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	l.logger.Debug(fmt.Sprintf(format, args...))
+}
+
+// LogrusLogger 把 logrus 风格的 logger 适配为 Logger，只需要实现 Debugf，
+// eg: logrus.WithField("module", "abi")，不需要依赖 logrus
+func LogrusLogger(logger interface {
+	Debugf(format string, args ...interface{})
+}) Logger {
+	if isNil(logger) {
+		return nopLogger{}
+	}
+	return logger
+}
+
+// isNil 同时判断接口本身与其中的 nil 指针，eg: (*logrus.Logger)(nil)
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
@@ -0,0 +1,297 @@
+package go_abi_param
+
+import (
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// NamedArgumentError 说明按名字传入的参数与函数定义不符
+type NamedArgumentError struct {
+	Method  string            // 函数签名
+	Missing []string          // 缺少的参数名
+	Extra   []string          // 函数中不存在的参数名
+	Suggest map[string]string // 拼写有误的参数名 => 最接近的参数名
+}
+
+func (e *NamedArgumentError) Error() string {
+	var msgs []string
+	if len(e.Missing) > 0 {
+		msgs = append(msgs, "missing "+strings.Join(e.Missing, ", "))
+	}
+	for _, name := range e.Extra {
+		if s, ok := e.Suggest[name]; ok {
+			msgs = append(msgs, fmt.Sprintf("unknown %s (did you mean %s?)", name, s))
+		} else {
+			msgs = append(msgs, "unknown "+name)
+		}
+	}
+	return fmt.Sprintf("%s: %s", e.Method, strings.Join(msgs, "; "))
+}
+
+// EncodeNamedCall 按参数名解析 JSON ABI 中某个函数的参数，返回按定义顺序排列的 go 值与 calldata。
+// values 的 key 为 ABI 中 inputs 的名字，tuple 可以整体传入（"params": "(0xabc,...)"），
+// 也可以按成员传入（"params.tokenIn": "0xabc"）。未命名的参数用位置作为 key，eg: "0"。
+// name 为函数名，有重载时先按参数名匹配，仍有多个时选出参数值能够解析的那一个，
+// 依然有歧义时可以传入完整签名，eg: safeTransferFrom(address,address,uint256)
+func EncodeNamedCall(contract abi.ABI, name string, values map[string]string) ([]interface{}, []byte, error) {
+	return defaultParser.EncodeNamedCall(contract, name, values)
+}
+
+// EncodeNamedCall 按 p 的设置解析参数并编码 calldata，见 EncodeNamedCall
+func (p *Parser) EncodeNamedCall(contract abi.ABI, name string, values map[string]string) (args []interface{}, data []byte, err error) {
+	defer recoverPanic(&err)
+	ap := p.newParam("", "")
+	size := len(name)
+	for key, value := range values {
+		size += len(key) + len(value)
+	}
+	if err := ap.checkInputSize(size); err != nil {
+		return nil, nil, err
+	}
+	method, args, err := resolveMethod(ap, contract, name, values)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	packed, err := method.Inputs.Pack(args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return args, append(method.ID, packed...), nil
+}
+
+// resolveMethod 按名字查找函数并解析参数，有重载时选出参数名完全匹配的那一个，
+// 参数名相同、类型不同的重载按参数值能否解析区分，eg: f(uint256 x) 与 f(bool x) 的 x 为 true 时选择后者
+func resolveMethod(ap *AbiParam, contract abi.ABI, name string, values map[string]string) (abi.Method, []interface{}, error) {
+	var candidates []abi.Method
+	for _, method := range contract.Methods {
+		if method.RawName == name || method.Sig == name {
+			candidates = append(candidates, method)
+		}
+	}
+	if len(candidates) == 0 {
+		return abi.Method{}, nil, fmt.Errorf("abi: method %s not found", name)
+	}
+	for _, method := range candidates {
+		if err := checkArgumentsSize(method.Inputs); err != nil {
+			return abi.Method{}, nil, fmt.Errorf("%s: %s", method.Sig, err)
+		}
+		if err := ap.checkArgumentLimits(method.Inputs, nil); err != nil {
+			return abi.Method{}, nil, err
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Sig < candidates[j].Sig })
+
+	var (
+		matched []abi.Method
+		best    *NamedArgumentError
+	)
+	for _, method := range candidates {
+		nErr := checkNames(method, values)
+		if nErr == nil {
+			matched = append(matched, method)
+			continue
+		}
+		if best == nil || len(nErr.Missing)+len(nErr.Extra) < len(best.Missing)+len(best.Extra) {
+			best = nErr
+		}
+	}
+
+	switch len(matched) {
+	case 0:
+		return abi.Method{}, nil, best
+	case 1:
+		args, err := parseNamed(ap, matched[0], values)
+		return matched[0], args, err
+	}
+
+	var (
+		parsed []abi.Method
+		args   []interface{}
+	)
+	for _, method := range matched {
+		if v, err := parseNamed(ap, method, values); err == nil {
+			parsed, args = append(parsed, method), v
+		}
+	}
+	if len(parsed) == 1 {
+		return parsed[0], args, nil
+	}
+	if len(parsed) > 1 {
+		matched = parsed
+	}
+	sigs := make([]string, len(matched))
+	for i, m := range matched {
+		sigs[i] = m.Sig
+	}
+	return abi.Method{}, nil, fmt.Errorf("abi: method %s is ambiguous, use one of %s", name, strings.Join(sigs, ", "))
+}
+
+// parseNamed 按 method 的参数定义解析 values，返回按定义顺序排列的 go 值
+func parseNamed(ap *AbiParam, method abi.Method, values map[string]string) ([]interface{}, error) {
+	w := &namedWalker{ap: ap, values: values, used: map[string]bool{}, parse: true}
+	args := make([]interface{}, len(method.Inputs))
+	for i, input := range method.Inputs {
+		w.index = i
+		args[i] = w.walk(&method.Inputs[i].Type, argumentKey(input, i))
+	}
+	if len(w.errs) > 0 {
+		return nil, w.errs
+	}
+	return args, nil
+}
+
+// checkNames 只检查参数名，不解析参数值
+func checkNames(method abi.Method, values map[string]string) *NamedArgumentError {
+	w := &namedWalker{values: values, used: map[string]bool{}}
+	for i, input := range method.Inputs {
+		w.walk(&method.Inputs[i].Type, argumentKey(input, i))
+	}
+
+	nErr := &NamedArgumentError{Method: method.Sig, Missing: w.missing, Suggest: map[string]string{}}
+	for key := range values {
+		if !w.used[key] {
+			nErr.Extra = append(nErr.Extra, key)
+		}
+	}
+	if len(nErr.Missing) == 0 && len(nErr.Extra) == 0 {
+		return nil
+	}
+	sort.Strings(nErr.Extra)
+
+	names := argumentNames(method.Inputs)
+	for _, key := range nErr.Extra {
+		if s, ok := closestName(key, names); ok {
+			nErr.Suggest[key] = s
+		}
+	}
+	return nErr
+}
+
+func argumentKey(arg abi.Argument, index int) string {
+	if arg.Name == "" {
+		return strconv.Itoa(index)
+	}
+	return arg.Name
+}
+
+// namedWalker 按类型遍历参数名，parse 为 true 时同时解析参数值
+type namedWalker struct {
+	ap      *AbiParam
+	values  map[string]string
+	used    map[string]bool
+	missing []string
+	parse   bool
+	index   int // 当前解析的参数位置
+	errs    ArgumentErrors
+}
+
+func (w *namedWalker) walk(typ *abi.Type, key string) interface{} {
+	if value, ok := w.values[key]; ok {
+		w.used[key] = true
+		if !w.parse {
+			return nil
+		}
+		ret, err := w.ap.parseType(typ, value)
+		if err != nil {
+			w.errs = append(w.errs, &ArgumentError{Index: w.index, Name: key, Err: err})
+		}
+		return ret
+	}
+
+	// tuple 可以按成员传入
+	if typ.T == abi.TupleTy && w.hasPrefix(key+".") {
+		fields := make([]interface{}, len(typ.TupleElems))
+		for i, elem := range typ.TupleElems {
+			fields[i] = w.walk(elem, key+"."+typ.TupleRawNames[i])
+		}
+		// 所有成员都解析成功后再分配 tuple，失败时结果会被丢弃
+		if !w.parse || len(w.errs) > 0 || len(w.missing) > 0 {
+			return nil
+		}
+		tuple := reflect.New(typ.TupleType).Elem()
+		for i, field := range fields {
+			tuple.Field(i).Set(reflect.ValueOf(field))
+		}
+		return tuple.Interface()
+	}
+
+	w.missing = append(w.missing, key)
+	return nil
+}
+
+func (w *namedWalker) hasPrefix(prefix string) bool {
+	for key := range w.values {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// argumentNames 列出所有可用的参数名，包括 tuple 成员，eg: params、params.tokenIn
+func argumentNames(args abi.Arguments) []string {
+	var names []string
+	var collect func(typ abi.Type, key string)
+	collect = func(typ abi.Type, key string) {
+		names = append(names, key)
+		if typ.T == abi.TupleTy {
+			for i, elem := range typ.TupleElems {
+				collect(*elem, key+"."+typ.TupleRawNames[i])
+			}
+		}
+	}
+	for i, arg := range args {
+		collect(arg.Type, argumentKey(arg, i))
+	}
+	return names
+}
+
+// closestName 找出编辑距离最小的参数名，距离超过名字长度的一半时认为不是拼写错误
+func closestName(key string, names []string) (string, bool) {
+	best, bestDist := "", -1
+	for _, name := range names {
+		d := editDistance(strings.ToLower(key), strings.ToLower(name))
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = name, d
+		}
+	}
+	if bestDist < 0 || bestDist > len(key)/2 {
+		return "", false
+	}
+	return best, true
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
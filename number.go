@@ -0,0 +1,209 @@
+package go_abi_param
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// defaultUnits 以太坊的金额单位，值为相对 wei 的小数位数，可以通过 WithUnits 扩展
+var defaultUnits = map[string]int{
+	"wei":        0,
+	"kwei":       3,
+	"babbage":    3,
+	"mwei":       6,
+	"lovelace":   6,
+	"gwei":       9,
+	"shannon":    9,
+	"szabo":      12,
+	"microether": 12,
+	"finney":     15,
+	"milliether": 15,
+	"ether":      18,
+}
+
+// maxExponent 科学计数法指数的上限，256 位整数最多 78 位十进制数，超出的指数没有意义且计算开销很大
+const maxExponent = 1000
+
+// readAmount 精确解析整数参数：
+// 1. 带单位的金额：1.5ether、1.5 ether、20gwei、3wei
+// 2. 设置了 decimals 的代币数量：decimals 为 6 时 123.45 => 123450000，100 => 100000000
+// 3. 科学计数法：1e18、1.234567890123456789e30、2.5e-3ether，按有理数精确计算
+// 4. 其它情况按整数字面量解析，见 parseIntegerLiteral
+// 结果不是整数（小数位超出精度）时报错，不会截断
+func readAmount(value string, decimals int, units map[string]int) (*big.Int, error) {
+	number, unit := splitUnit(value, units)
+	if unit != "" {
+		return parseDecimal(number, units[unit])
+	}
+	if mantissa, _, _ := splitExponent(value); isDecimal(mantissa) {
+		return parseDecimal(value, decimals)
+	}
+	return readBigInt(value)
+}
+
+// splitUnit 拆分数值与单位，单位不区分大小写
+func splitUnit(value string, units map[string]int) (string, string) {
+	i := len(value)
+	for i > 0 && isLetter(value[i-1]) {
+		i--
+	}
+	unit := strings.ToLower(value[i:])
+	if _, ok := units[unit]; !ok {
+		return value, ""
+	}
+	return strings.TrimSpace(value[:i]), unit
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// splitExponent 拆分科学计数法的尾数与指数，eg: 1.5e18 => 1.5, 18
+func splitExponent(value string) (string, int, error) {
+	i := strings.LastIndexAny(value, "eE")
+	if i < 0 {
+		return value, 0, nil
+	}
+	exp, err := strconv.Atoi(value[i+1:])
+	if err != nil {
+		return value[:i], 0, fmt.Errorf("param %s has invalid exponent", value)
+	}
+	if exp > maxExponent || exp < -maxExponent {
+		return value[:i], 0, rangeError("param %s exponent out of range [-%d, %d]", value, maxExponent, maxExponent)
+	}
+	return value[:i], exp, nil
+}
+
+// trimSign 去掉最多一个开头的 - 或 +，eg: -+5 只去掉 -
+func trimSign(value string) (string, bool) {
+	if strings.HasPrefix(value, "-") {
+		return value[1:], true
+	}
+	return strings.TrimPrefix(value, "+"), false
+}
+
+// isDecimal 判断 value 是否为十进制数，eg: 123、-1.5、1_000.25
+func isDecimal(value string) bool {
+	s, _ := trimSign(value)
+	if s == "" {
+		return false
+	}
+	dot, digit := false, false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] >= '0' && s[i] <= '9':
+			digit = true
+		case s[i] == '_':
+		case s[i] == '.' && !dot:
+			dot = true
+		default:
+			return false
+		}
+	}
+	return digit
+}
+
+// parseDecimal 把十进制数（可带指数）放大 10^scale 倍，结果必须是整数
+func parseDecimal(value string, scale int) (*big.Int, error) {
+	mantissa, exp, err := splitExponent(value)
+	if err != nil {
+		return nil, err
+	}
+	if !isDecimal(mantissa) {
+		return nil, fmt.Errorf("param %s can not convent to decimal", value)
+	}
+	s, neg := trimSign(mantissa)
+
+	intPart, fracPart := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	// _ 不能紧挨着小数点
+	if strings.HasSuffix(intPart, "_") || strings.HasPrefix(fracPart, "_") {
+		return nil, fmt.Errorf("param %s can not convent to decimal", value)
+	}
+	fracPart = strings.ReplaceAll(fracPart, "_", "")
+	if intPart == "" {
+		intPart = "0"
+	}
+
+	v, ok := parseIntegerLiteral(intPart + fracPart)
+	if !ok {
+		return nil, fmt.Errorf("param %s can not convent to decimal", value)
+	}
+
+	// value = digits * 10^(scale + exp - len(fracPart))
+	shift := scale + exp - len(fracPart)
+	if shift > 0 {
+		v.Mul(v, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(shift)), nil))
+	} else if shift < 0 {
+		divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-shift)), nil)
+		quo, rem := new(big.Int).QuoRem(v, divisor, new(big.Int))
+		if rem.Sign() != 0 {
+			return nil, rangeError("param %s is not an integer with %d decimals, would lose precision", value, scale)
+		}
+		v = quo
+	}
+	if neg {
+		v.Neg(v)
+	}
+	return v, nil
+}
+
+// parseIntegerLiteral 解析整数字面量，支持：
+// 十进制  1000、-1000
+// 十六进制 0xff、-0x10
+// 二进制  0b1010
+// 八进制  0o17
+// 以及数字之间的 _ 分隔符，eg: 1_000_000、0xffff_ffff
+func parseIntegerLiteral(value string) (*big.Int, bool) {
+	s, neg := trimSign(value)
+
+	base := 10
+	if len(s) >= 2 && s[0] == '0' {
+		switch s[1] {
+		case 'x', 'X':
+			base = 16
+		case 'b', 'B':
+			base = 2
+		case 'o', 'O':
+			base = 8
+		}
+		if base != 10 {
+			s = s[2:]
+		}
+	}
+
+	// _ 只能出现在两个数字之间
+	if strings.Contains(s, "_") {
+		if strings.HasPrefix(s, "_") || strings.HasSuffix(s, "_") || strings.Contains(s, "__") {
+			return nil, false
+		}
+		s = strings.ReplaceAll(s, "_", "")
+	}
+	// 符号已经处理过，SetString 不应再看到符号
+	if s == "" || s[0] == '+' || s[0] == '-' {
+		return nil, false
+	}
+
+	// 常见的十进制小整数不经过 big.Int.SetString，后者每次都会分配 Reader
+	if base == 10 && len(s) <= 19 {
+		if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+			v := new(big.Int).SetUint64(u)
+			if neg {
+				v.Neg(v)
+			}
+			return v, true
+		}
+	}
+	v, ok := new(big.Int).SetString(s, base)
+	if !ok {
+		return nil, false
+	}
+	if neg {
+		v.Neg(v)
+	}
+	return v, true
+}
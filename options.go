@@ -0,0 +1,118 @@
+package go_abi_param
+
+import (
+	"strings"
+)
+
+// config 是 Parser 与 AbiParam 共用的解析设置
+type config struct {
+	decimals int             // 整数类型的小数位数，eg: USDC 为 6，此时 123.45 解析为 123450000
+	padding  Padding         // bytesN 的补齐方式，默认不补齐
+	address  *AddressOptions // 非空时严格校验地址，见 SetStrictAddress
+	resolver Resolver        // 非空时地址参数可以是名字，见 SetResolver
+	units    map[string]int  // 金额单位 => 相对 wei 的小数位数
+	limits   Limits          // 资源限制，见 Limits
+	logger   Logger
+}
+
+func defaultConfig() config {
+	return config{units: defaultUnits, limits: DefaultLimits, logger: nopLogger{}}
+}
+
+// Limits 解析不可信输入时的资源限制，0 为不限制，超出时返回 Kind 为 ErrLimit 的 *ParseError。
+// 嵌套层数总有上限：MaxDepth 为 0 时按类型的最大嵌套层数限制，更深的 value 不可能与任何类型匹配
+// 面向公网的服务建议全部设置，eg:
+//
+//	NewParser(WithLimits(Limits{MaxInputSize: 1 << 20, MaxDepth: 16, MaxArrayLength: 100000, MaxBytesLength: 64 << 10}))
+type Limits struct {
+	MaxInputSize   int // blob 与 value 的总字节数
+	MaxDepth       int // 数组与 tuple 的嵌套层数，0 时按类型的最大嵌套层数（128）限制
+	MaxArrayLength int // 单个数组、tuple 或参数列表的成员个数
+	MaxBytesLength int // 单个 bytes 或 string 的字节数
+}
+
+// DefaultLimits 默认只限制嵌套层数，避免过深的 value 导致递归栈溢出
+var DefaultLimits = Limits{MaxDepth: 64}
+
+// Option 是 NewParser 的设置项
+type Option func(*config)
+
+// WithDecimals 见 AbiParam.SetDecimals
+func WithDecimals(decimals int) Option {
+	return func(c *config) {
+		c.decimals = decimals
+	}
+}
+
+// WithPadding 见 AbiParam.SetPadding
+func WithPadding(padding Padding) Option {
+	return func(c *config) {
+		c.padding = padding
+	}
+}
+
+// WithStrict 开启严格的地址校验，等价于 WithAddressOptions(AddressOptions{})
+func WithStrict() Option {
+	return WithAddressOptions(AddressOptions{})
+}
+
+// WithAddressOptions 开启严格的地址校验，见 AbiParam.SetStrictAddress
+func WithAddressOptions(opts AddressOptions) Option {
+	return func(c *config) {
+		c.address = &opts
+	}
+}
+
+// WithResolver 见 AbiParam.SetResolver
+func WithResolver(resolver Resolver) Option {
+	return func(c *config) {
+		c.resolver = resolver
+	}
+}
+
+// WithMaxDepth 限制 value 中数组与 tuple 的嵌套层数，默认为 DefaultLimits.MaxDepth，0 时按类型的最大嵌套层数限制
+func WithMaxDepth(depth int) Option {
+	return func(c *config) {
+		c.limits.MaxDepth = depth
+	}
+}
+
+// WithLimits 设置全部资源限制，见 Limits，未设置的项（0）不限制。
+// limits 整体替换 DefaultLimits，MaxDepth 为 0 不会保留默认的 64，需要时显式设置
+func WithLimits(limits Limits) Option {
+	return func(c *config) {
+		c.limits = limits
+	}
+}
+
+// maxDepth 返回 value 实际允许的嵌套层数，见 Limits.MaxDepth
+func (l Limits) maxDepth() int {
+	if l.MaxDepth > 0 && l.MaxDepth < maxValueDepth {
+		return l.MaxDepth
+	}
+	return maxValueDepth
+}
+
+// WithUnits 添加或覆盖金额单位，值为相对最小单位的小数位数，eg: {"usdc": 6} 后 1.5usdc => 1500000
+func WithUnits(units map[string]int) Option {
+	return func(c *config) {
+		merged := make(map[string]int, len(c.units)+len(units))
+		for name, scale := range c.units {
+			merged[name] = scale
+		}
+		for name, scale := range units {
+			merged[strings.ToLower(name)] = scale
+		}
+		c.units = merged
+	}
+}
+
+// WithLogger 设置调试日志使用的 logger，eg: WithLogger(SlogLogger(slog.Default()))、WithLogger(logrus.New())
+func WithLogger(logger Logger) Option {
+	return func(c *config) {
+		if logger == nil {
+			logger = nopLogger{}
+		}
+		c.logger = logger
+	}
+}
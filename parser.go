@@ -1,8 +1,10 @@
 package go_abi_param
 
 import (
+	"encoding/json"
 	"errors"
-	"github.com/sirupsen/logrus"
+	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 )
 
 var (
@@ -11,14 +13,26 @@ var (
 	errBadBool  = errors.New("param: improperly encoded boolean value")
 )
 
+// Padding 是 bytesN 的输入短于 N 字节时的补齐方式
+type Padding int
+
+const (
+	PadNone  Padding = iota // 不补齐，长度不符时报错
+	PadRight                // 在右侧补零，与 solidity 中 bytes4(0x12) 的转换一致，eg: 0x12 => 0x12000000
+	PadLeft                 // 在左侧补零，eg: 0x12 => 0x00000012
+)
+
 type AbiParam struct {
-	blob   string
-	value  string
-	logger *logrus.Logger
+	blob  string
+	value string
+	json  bool       // value 为 JSON，见 NewAbiParamJSON
+	fixed fixedTypes // 正在解析的类型中的定点数
+	config
 }
 
+// NewAbiParam 使用默认设置创建 AbiParam，需要复用设置或在多个 goroutine 中解析时使用 Parser
 func NewAbiParam(blob string, value string) (*AbiParam, error) {
-	ap := &AbiParam{blob: blob, value: value, logger: logrus.New()}
+	ap := defaultParser.newParam(blob, value)
 	return ap, ap.check()
 }
 
@@ -33,6 +47,147 @@ func (ap *AbiParam) check() error {
 	return nil
 }
 
-func (ap *AbiParam) Parse() (interface{}, error) {
+// SetDecimals 设置代币的小数位数，设置后十进制的整数参数按代币数量解析，eg: decimals 为 6 时 123.45 => 123450000
+func (ap *AbiParam) SetDecimals(decimals int) *AbiParam {
+	ap.decimals = decimals
+	return ap
+}
+
+// SetPadding 设置 bytesN 的输入短于 N 字节时的补齐方式，默认长度不符时报错
+func (ap *AbiParam) SetPadding(padding Padding) *AbiParam {
+	ap.padding = padding
+	return ap
+}
+
+// SetLimits 设置解析不可信输入时的资源限制，见 Limits
+func (ap *AbiParam) SetLimits(limits Limits) *AbiParam {
+	ap.limits = limits
+	return ap
+}
+
+// Parse 解析 value，任何输入都不会 panic，解析过程中出现的 panic 以错误返回
+func (ap *AbiParam) Parse() (ret interface{}, err error) {
+	defer recoverPanic(&err)
+	if err := ap.checkInputSize(len(ap.blob) + len(ap.value)); err != nil {
+		return nil, err
+	}
 	return ap.parseParam(ap.blob, ap.value)
 }
+
+// checkInputSize 检查输入的总字节数是否超出 MaxInputSize
+func (ap *AbiParam) checkInputSize(size int) error {
+	if ap.limits.MaxInputSize > 0 && size > ap.limits.MaxInputSize {
+		return limitError(0, "input is %d bytes, limit is %d", size, ap.limits.MaxInputSize)
+	}
+	return nil
+}
+
+// typeOf 返回 blob 对应的 abi.Type，同一个 blob 只解析一次，见 cachedType
+func (ap *AbiParam) typeOf(blob string) (*paramType, error) {
+	return cachedType(blob)
+}
+
+// parseValue 按输入模式解析 ap.value
+func (ap *AbiParam) parseValue(typ *abi.Type) (interface{}, error) {
+	if ap.json {
+		return ap.parseJSON(typ, ap.value)
+	}
+	return ap.parseType(typ, ap.value)
+}
+
+// Parser 可以复用的解析器，设置在创建时通过 Option 指定，之后可以在多个 goroutine 中同时使用，eg:
+//
+//	p := NewParser(WithDecimals(6), WithStrict())
+//	v, err := p.Parse("uint256[]", "[1.5,2]")
+type Parser struct {
+	config
+}
+
+var defaultParser = NewParser()
+
+func NewParser(opts ...Option) *Parser {
+	p := &Parser{config: defaultConfig()}
+	for _, opt := range opts {
+		opt(&p.config)
+	}
+	return p
+}
+
+func (p *Parser) newParam(blob, value string) *AbiParam {
+	return &AbiParam{blob: blob, value: value, config: p.config}
+}
+
+// Parse 解析 value，与 NewAbiParam(blob, value).Parse() 相同
+func (p *Parser) Parse(blob, value string) (interface{}, error) {
+	ap := p.newParam(blob, value)
+	if err := ap.check(); err != nil {
+		return nil, err
+	}
+	return ap.Parse()
+}
+
+// ParseJSON 解析 JSON 形式的 value，见 NewAbiParamJSON
+func (p *Parser) ParseJSON(blob, value string) (interface{}, error) {
+	ap := p.newParam(blob, value)
+	ap.json = true
+	if err := ap.check(); err != nil {
+		return nil, err
+	}
+	return ap.Parse()
+}
+
+// ParseInto 解析 value 并写入 dst，见 AbiParam.ParseInto
+func (p *Parser) ParseInto(blob, value string, dst interface{}) error {
+	ap := p.newParam(blob, value)
+	if err := ap.check(); err != nil {
+		return err
+	}
+	return ap.ParseInto(dst)
+}
+
+// ParseArguments 一次解析整个参数列表，见 ParseArguments
+func (p *Parser) ParseArguments(args interface{}, values interface{}) (ret []interface{}, err error) {
+	defer recoverPanic(&err)
+	ap := p.newParam("", "")
+	if err := ap.checkInputSize(argumentsSize(args, values)); err != nil {
+		return nil, err
+	}
+	arguments, fixed, err := toArguments(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := ap.checkArgumentLimits(arguments, fixed); err != nil {
+		return nil, err
+	}
+	ap.fixed = fixed
+
+	switch v := values.(type) {
+	case []string:
+		return ap.parseArguments(arguments, v)
+	case string:
+		return ap.parseArgumentString(arguments, v)
+	case json.RawMessage:
+		return ap.parseArgumentJSON(arguments, string(v))
+	default:
+		return nil, fmt.Errorf("values should be []string, string or json.RawMessage, got %T", values)
+	}
+}
+
+// argumentsSize 返回参数类型与参数值的总字节数，用于 MaxInputSize
+func argumentsSize(args interface{}, values interface{}) int {
+	size := 0
+	if s, ok := args.(string); ok {
+		size += len(s)
+	}
+	switch v := values.(type) {
+	case []string:
+		for _, s := range v {
+			size += len(s)
+		}
+	case string:
+		size += len(v)
+	case json.RawMessage:
+		size += len(v)
+	}
+	return size
+}
@@ -1,20 +1,27 @@
 package go_abi_param
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/magiconair/properties/assert"
+	"log/slog"
 	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 	"unsafe"
 )
 
+// parseTests 在包初始化阶段构造，这里的值不能放在 init 中赋值
 var (
-	biVal     *big.Int
-	bytesVal  []byte
-	byte32Val [32]byte
+	biVal     = big.NewInt(1000)
+	funcVal   = [24]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x6c, 0x38, 0x52, 0xcb, 0xef, 0x3e, 0x08, 0xe8, 0xdf, 0x28, 0x91, 0x69, 0xed, 0xe5, 0x81, 0xa9, 0x05, 0x9c, 0xbb}
+	bytesVal  = hexutil.MustDecode("0x9e99847ecf80af04f0808e017172bc71b71a5d1bb7b82ab1ce4b2ec666f009425419ad6e1d42c27f0d8408976e20276e5fd2411c6dc42d06d885b4c25d71fbb31c")
+	byte32Val = *byte32(hexutil.MustDecode("0x0000007b02230091a7ed01230072f7006a004d60a8d4e71d599b8104250f0000"))
 )
 
 func byte32(s []byte) (a *[32]byte) {
@@ -24,456 +31,1973 @@ func byte32(s []byte) (a *[32]byte) {
 	return a
 }
 
-func init() {
-	biVal, _ = new(big.Int).SetString("1000", 10)
-	bytesVal, _ = hexutil.Decode("0x9e99847ecf80af04f0808e017172bc71b71a5d1bb7b82ab1ce4b2ec666f009425419ad6e1d42c27f0d8408976e20276e5fd2411c6dc42d06d885b4c25d71fbb31c")
-	_byte32Val, _ := hexutil.Decode("0x0000007b02230091a7ed01230072f7006a004d60a8d4e71d599b8104250f0000")
-	byte32Val = *byte32(_byte32Val)
+type parseTest struct {
+	name       string
+	blob       string
+	value      string
+	goArgument string
+	want       interface{}
+}
+
+var parseTests = []parseTest{
+	{
+		name:       "error: abi unsupported int",
+		blob:       "int",
+		goArgument: "error",
+		value:      "1000",
+	},
+	{
+		name:       "normal: int8",
+		blob:       "int8",
+		value:      "3",
+		goArgument: "int8",
+		want:       int8(3),
+	},
+	{
+		name:       "normal: int16",
+		blob:       "int16",
+		value:      "11",
+		goArgument: "int16",
+		want:       int16(11),
+	},
+	{
+		name:       "normal: int32",
+		blob:       "int32",
+		value:      "1122",
+		goArgument: "int32",
+		want:       int32(1122),
+	},
+	{
+		name:       "normal: int64",
+		blob:       "int64",
+		value:      "111111",
+		goArgument: "int64",
+		want:       int64(111111),
+	},
+	{
+		name:       "normal: int128",
+		blob:       "int128",
+		value:      "1000",
+		goArgument: "*big.Int",
+		want:       biVal,
+	},
+	{
+		name:       "normal: int256",
+		blob:       "int256",
+		value:      "1000",
+		goArgument: "*big.Int",
+		want:       biVal,
+	},
+	{
+		name:       "normal: uint8",
+		blob:       "uint8",
+		value:      "3",
+		goArgument: "uint8",
+		want:       uint8(3),
+	},
+	{
+		name:       "normal: uint16",
+		blob:       "uint16",
+		value:      "20",
+		goArgument: "uint16",
+		want:       uint16(20),
+	},
+	{
+		name:       "normal: uint32",
+		blob:       "uint32",
+		value:      "100",
+		goArgument: "uint32",
+		want:       uint32(100),
+	},
+	{
+		name:       "normal: uint64",
+		blob:       "uint64",
+		value:      "100",
+		goArgument: "uint64",
+		want:       uint64(100),
+	},
+	{
+		name:       "normal: uint128",
+		blob:       "uint128",
+		value:      "1000",
+		goArgument: "*big.Int",
+		want:       biVal,
+	},
+	{
+		name:       "normal: uint256",
+		blob:       "uint256",
+		value:      "1000",
+		goArgument: "*big.Int",
+		want:       biVal,
+	},
+	{
+		name:       "normal: uint256 trailing newline",
+		blob:       "uint256",
+		value:      "1000\n",
+		goArgument: "*big.Int",
+		want:       biVal,
+	},
+	{
+		name:       "error: uint, abi unSupported",
+		blob:       "uint",
+		value:      "1000",
+		goArgument: "uint",
+	},
+	{
+		name:       "normal: address",
+		blob:       "address",
+		value:      "0x00000000006c3852cbef3e08e8df289169ede581",
+		goArgument: "common.Address",
+		want:       common.HexToAddress("0x00000000006c3852cbef3e08e8df289169ede581"),
+	},
+	{
+		name:       "normal: address[]",
+		blob:       "address[]",
+		value:      `["0x00000000006c3852cbef3e08e8df289169ede581","0x1b2667862b2a4f46DfD6C53f561C58a8B0EED0D6"]`,
+		goArgument: "[]common.Address",
+		want: []common.Address{common.HexToAddress("0x00000000006c3852cbef3e08e8df289169ede581"),
+			common.HexToAddress("0x1b2667862b2a4f46DfD6C53f561C58a8B0EED0D6")},
+	},
+	{
+		name:       "error: unsupported int[] type",
+		blob:       "int[]",
+		value:      "[1,2]",
+		goArgument: "int[]",
+	},
+	{
+		name:       "normal: int8[]",
+		blob:       "int8[]",
+		value:      "[1,3]",
+		goArgument: "[]int8",
+		want:       []int8{1, 3},
+	},
+	{
+		name:       "normal: int16[]",
+		blob:       "int16[]",
+		value:      "[3,233]",
+		goArgument: "[]int16",
+		want:       []int16{3, 233},
+	},
+	{
+		name:       "normal: int32[]",
+		blob:       "int32[]",
+		value:      "3,344",
+		goArgument: "[]int32",
+		want:       []int32{3, 344},
+	},
+	{
+		name:       "normal: int64[]",
+		blob:       "int64[]",
+		value:      "1000,10001",
+		goArgument: "[]int64",
+		want:       []int64{1000, 10001},
+	},
+	{
+		name:       "normal: int128[]",
+		blob:       "int128[]",
+		value:      "[1000]",
+		goArgument: "[]*big.Int",
+		want:       []*big.Int{biVal},
+	},
+	{
+		name:       "normal: int256[]",
+		blob:       "int256[]",
+		value:      "[1000]",
+		goArgument: "[]*big.Int",
+		want:       []*big.Int{biVal},
+	},
+	{
+		name:       "error: unSupported uint[] type",
+		blob:       "uint[]",
+		value:      "",
+		goArgument: "uint64",
+	},
+	{
+		name:       "normal: uint8[]",
+		blob:       "uint8[]",
+		value:      "[1,2,3]",
+		goArgument: "[]uint8",
+		want:       []uint8{1, 2, 3},
+	},
+	{
+		name:       "normal: uint16[]",
+		blob:       "uint16[]",
+		value:      "[4,5,6]",
+		goArgument: "[]uint16",
+		want:       []uint16{4, 5, 6},
+	},
+	{
+		name:       "normal: uint32[]",
+		blob:       "uint32[]",
+		value:      "[100,200,400]",
+		goArgument: "[]uint32",
+		want:       []uint32{100, 200, 400},
+	},
+	{
+		name:       "normal: uint64[]",
+		blob:       "uint64[]",
+		value:      "[1000,2000]",
+		goArgument: "[]uint64",
+		want:       []uint64{1000, 2000},
+	},
+	{
+		name:       "normal: uint128[]",
+		blob:       "uint128[]",
+		value:      "1000",
+		goArgument: "[]*big.Int",
+		want:       []*big.Int{biVal},
+	},
+	{
+		name:       "normal: uint256[]",
+		blob:       "uint256[]",
+		value:      "1000",
+		goArgument: "[]*big.Int",
+		want:       []*big.Int{biVal},
+	},
+	{
+		name:       "normal: bytes",
+		blob:       "bytes",
+		value:      "0x9e99847ecf80af04f0808e017172bc71b71a5d1bb7b82ab1ce4b2ec666f009425419ad6e1d42c27f0d8408976e20276e5fd2411c6dc42d06d885b4c25d71fbb31c",
+		goArgument: "[]uint8",
+		want:       bytesVal,
+	},
+	{
+		name:       "normal: slice bytes",
+		blob:       "bytes[]",
+		value:      "[0x9e99847ecf80af04f0808e017172bc71b71a5d1bb7b82ab1ce4b2ec666f009425419ad6e1d42c27f0d8408976e20276e5fd2411c6dc42d06d885b4c25d71fbb31c,0x9e99847ecf80af04f0808e017172bc71b71a5d1bb7b82ab1ce4b2ec666f009425419ad6e1d42c27f0d8408976e20276e5fd2411c6dc42d06d885b4c25d71fbb31c]",
+		goArgument: "[][]uint8",
+		want:       [][]uint8{bytesVal, bytesVal},
+	},
+	{
+		name:       "normal: bytes32",
+		blob:       "bytes32",
+		value:      "0x0000007b02230091a7ed01230072f7006a004d60a8d4e71d599b8104250f0000",
+		goArgument: "[32]uint8",
+		want:       byte32Val,
+	},
+	{
+		name:       "normal: bytes32[]",
+		blob:       "bytes32[]",
+		value:      "[0x0000007b02230091a7ed01230072f7006a004d60a8d4e71d599b8104250f0000,0x0000007b02230091a7ed01230072f7006a004d60a8d4e71d599b8104250f0000]",
+		goArgument: "[][32]uint8",
+		want:       [][32]byte{byte32Val, byte32Val},
+	},
+	{
+		name:       "normal: string",
+		blob:       "string",
+		value:      "abcd434d32",
+		goArgument: "string",
+		want:       "abcd434d32",
+	},
+	{
+		name:       "normal: bool",
+		blob:       "bool",
+		value:      "1",
+		goArgument: "bool",
+		want:       true,
+	},
+	{
+		name:       "normal: bool",
+		blob:       "bool",
+		value:      "true",
+		goArgument: "bool",
+		want:       true,
+	},
+	{
+		name:       "normal: bool surrounding whitespace",
+		blob:       "bool",
+		value:      "\ttrue\n",
+		goArgument: "bool",
+		want:       true,
+	},
+	{
+		name:       "normal: array",
+		blob:       "address[3]",
+		value:      `0x543a5aed5abc902553a92547701ac38f73a70785,0x7d2768de32b0b80b7a3454c06bdac94a69ddc7a9,0x028171bCA77440897B824Ca71D1c56caC55b68A3`,
+		goArgument: "[3]common.Address",
+		want: [3]common.Address{common.HexToAddress("0x543a5aed5abc902553a92547701ac38f73a70785"),
+			common.HexToAddress("0x7d2768de32b0b80b7a3454c06bdac94a69ddc7a9"),
+			common.HexToAddress("0x028171bCA77440897B824Ca71D1c56caC55b68A3")},
+	},
+	{
+		name:       "normal: new fmt array",
+		blob:       "address[3]",
+		value:      `[0x543a5aed5abc902553a92547701ac38f73a70785,0x7d2768de32b0b80b7a3454c06bdac94a69ddc7a9,0x028171bCA77440897B824Ca71D1c56caC55b68A3]`,
+		goArgument: "[3]common.Address",
+		want: [3]common.Address{common.HexToAddress("0x543a5aed5abc902553a92547701ac38f73a70785"),
+			common.HexToAddress("0x7d2768de32b0b80b7a3454c06bdac94a69ddc7a9"),
+			common.HexToAddress("0x028171bCA77440897B824Ca71D1c56caC55b68A3")},
+	},
+	{
+		name:       "normal: slice && array with bool",
+		blob:       "bool[][2]",
+		value:      "[[1,0,1],[0,1]]",
+		goArgument: "[2][]bool",
+		want:       [2][]bool{{true, false, true}, {false, true}},
+	},
+	{
+		name:       "normal: slice && array with bool",
+		blob:       "bool[2][2]",
+		value:      "[[true,false],[false,true]]",
+		goArgument: "[2][2]bool",
+		want:       [2][2]bool{{true, false}, {false, true}},
+	},
+	{
+		name:       "normal: slice && array with string",
+		blob:       "string[][3]",
+		value:      `[[aaa,vvv,bbb],[w4f,6s%#],[14c14,c423,f34e&*^,fjhvfw]]`,
+		goArgument: "[3][]string",
+		want:       [3][]string{{"aaa", "vvv", "bbb"}, {"w4f", "6s%#"}, {"14c14", "c423", "f34e&*^", "fjhvfw"}},
+	},
+	{
+		name:  "error: slice && array, out of index",
+		blob:  "bool[][2]",
+		value: "[[1,0],[0,0,1],[1]]",
+		want:  false,
+	},
+	{
+		name:  "error: slice && array, arguments",
+		blob:  "bool[][2]",
+		value: "[[1,5],[2,4]]]",
+		want:  false,
+	},
+	{
+		name:       "nested array",
+		blob:       "int8[2][2][2]",
+		value:      "[[[1,2],[3,4]],[[5,6],[7,8]]]",
+		goArgument: "[2][2][2]int8",
+		want:       [2][2][2]int8{{{1, 2}, {3, 4}}, {{5, 6}, {7, 8}}},
+	},
+	{
+		name:       "normal: uint8 max",
+		blob:       "uint8",
+		value:      "255",
+		goArgument: "uint8",
+		want:       uint8(255),
+	},
+	{
+		name:  "error: uint8 overflow",
+		blob:  "uint8",
+		value: "300",
+	},
+	{
+		name:  "error: uint8 negative",
+		blob:  "uint8",
+		value: "-1",
+	},
+	{
+		name:       "normal: int8 min",
+		blob:       "int8",
+		value:      "-128",
+		goArgument: "int8",
+		want:       int8(-128),
+	},
+	{
+		name:  "error: int8 overflow",
+		blob:  "int8",
+		value: "128",
+	},
+	{
+		name:  "error: int16 underflow",
+		blob:  "int16",
+		value: "-32769",
+	},
+	{
+		name:  "error: uint32 overflow",
+		blob:  "uint32",
+		value: "4294967296",
+	},
+	{
+		name:       "normal: uint64 max",
+		blob:       "uint64",
+		value:      "18446744073709551615",
+		goArgument: "uint64",
+		want:       uint64(18446744073709551615),
+	},
+	{
+		name:  "error: int64 overflow",
+		blob:  "int64",
+		value: "9223372036854775808",
+	},
+	{
+		name:       "normal: uint24 max",
+		blob:       "uint24",
+		value:      "16777215",
+		goArgument: "*big.Int",
+		want:       big.NewInt(16777215),
+	},
+	{
+		name:  "error: uint24 overflow",
+		blob:  "uint24",
+		value: "16777216",
+	},
+	{
+		name:  "error: int72 overflow",
+		blob:  "int72",
+		value: "2361183241434822606848",
+	},
+	{
+		name:       "normal: int256 min",
+		blob:       "int256",
+		value:      "-57896044618658097711785492504343953926634992332820282019728792003956564819968",
+		goArgument: "*big.Int",
+		want:       new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 255)),
+	},
+	{
+		name:  "error: int256 overflow",
+		blob:  "int256",
+		value: "57896044618658097711785492504343953926634992332820282019728792003956564819968",
+	},
+	{
+		name:       "normal: uint256 max",
+		blob:       "uint256",
+		value:      "115792089237316195423570985008687907853269984665640564039457584007913129639935",
+		goArgument: "*big.Int",
+		want:       new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)),
+	},
+	{
+		name:  "error: uint256 overflow",
+		blob:  "uint256",
+		value: "115792089237316195423570985008687907853269984665640564039457584007913129639936",
+	},
+	{
+		name:  "error: uint128 negative",
+		blob:  "uint128",
+		value: "-1",
+	},
+	{
+		name:  "error: uint8[] element overflow",
+		blob:  "uint8[]",
+		value: "[1,256]",
+	},
+	{
+		name:       "normal: uint8 hex",
+		blob:       "uint8",
+		value:      "0xff",
+		goArgument: "uint8",
+		want:       uint8(255),
+	},
+	{
+		name:       "normal: int16 negative hex",
+		blob:       "int16",
+		value:      "-0x10",
+		goArgument: "int16",
+		want:       int16(-16),
+	},
+	{
+		name:       "normal: uint32 binary",
+		blob:       "uint32",
+		value:      "0b1010",
+		goArgument: "uint32",
+		want:       uint32(10),
+	},
+	{
+		name:       "normal: uint64 octal",
+		blob:       "uint64",
+		value:      "0o17",
+		goArgument: "uint64",
+		want:       uint64(15),
+	},
+	{
+		name:       "normal: uint256 underscore",
+		blob:       "uint256",
+		value:      "1_000",
+		goArgument: "*big.Int",
+		want:       biVal,
+	},
+	{
+		name:       "normal: int128 hex underscore",
+		blob:       "int128",
+		value:      "0x3_e8",
+		goArgument: "*big.Int",
+		want:       biVal,
+	},
+	{
+		name:       "normal: uint8[] literals",
+		blob:       "uint8[]",
+		value:      "[0x01,0b10,0o3,1_0]",
+		goArgument: "[]uint8",
+		want:       []uint8{1, 2, 3, 10},
+	},
+	{
+		name:  "error: uint8 hex overflow",
+		blob:  "uint8",
+		value: "0x100",
+	},
+	{
+		name:  "error: double underscore",
+		blob:  "uint256",
+		value: "1__000",
+	},
+	{
+		name:  "error: trailing underscore",
+		blob:  "uint256",
+		value: "1000_",
+	},
+	{
+		name:  "error: empty hex",
+		blob:  "uint256",
+		value: "0x",
+	},
+	{
+		name:  "error: bad hex digit",
+		blob:  "uint64",
+		value: "0xfg",
+	},
+	{
+		name:       "normal: empty slice",
+		blob:       "uint8[]",
+		value:      "[]",
+		goArgument: "[]uint8",
+		want:       []uint8{},
+	},
+	{
+		name:       "normal: empty nested slice",
+		blob:       "uint8[][]",
+		value:      "[[],[1]]",
+		goArgument: "[][]uint8",
+		want:       [][]uint8{{}, {1}},
+	},
+	{
+		name:       "normal: quoted string with delimiters",
+		blob:       "string[]",
+		value:      `["a,b","[c]","(d)"]`,
+		goArgument: "[]string",
+		want:       []string{"a,b", "[c]", "(d)"},
+	},
+	{
+		name:       "normal: string keeps spaces",
+		blob:       "string",
+		value:      "hello world",
+		goArgument: "string",
+		want:       "hello world",
+	},
+	{
+		name:       "normal: quoted top-level string",
+		blob:       "string",
+		value:      `"a,b\n"`,
+		goArgument: "string",
+		want:       "a,b\n",
+	},
+	{
+		name:       "normal: escaped strings",
+		blob:       "string[]",
+		value:      `["hello world", "  padded  ", "tab\there", "caf\u00e9", "\ud83d\ude00", "back\\slash", "say \"hi\""]`,
+		goArgument: "[]string",
+		want:       []string{"hello world", "  padded  ", "tab\there", "café", "😀", "back\\slash", `say "hi"`},
+	},
+	{
+		name:       "normal: words keep inner spaces",
+		blob:       "string[][2]",
+		value:      "[[hello world , foo], [ a  b ]]",
+		goArgument: "[2][]string",
+		want:       [2][]string{{"hello world", "foo"}, {"a  b"}},
+	},
+	{
+		name:  "error: invalid escape",
+		blob:  "string[]",
+		value: `["\x41"]`,
+	},
+	{
+		name:  "error: short unicode escape",
+		blob:  "string[]",
+		value: `["\u12"]`,
+	},
+	{
+		name:  "error: lone surrogate",
+		blob:  "string[]",
+		value: `["\ud83d"]`,
+	},
+	{
+		name:       "normal: bytes1",
+		blob:       "bytes1",
+		value:      "0x7f",
+		goArgument: "[1]uint8",
+		want:       [1]byte{0x7f},
+	},
+	{
+		name:       "normal: bytes4[]",
+		blob:       "bytes4[]",
+		value:      "[0xa9059cbb,0x095ea7b3]",
+		goArgument: "[][4]uint8",
+		want:       [][4]byte{{0xa9, 0x05, 0x9c, 0xbb}, {0x09, 0x5e, 0xa7, 0xb3}},
+	},
+	{
+		name:       "normal: nested bytesN",
+		blob:       "(bytes4,bytes2[2])[]",
+		value:      "[(0xa9059cbb,[0x0102,0x0304])]",
+		goArgument: "[]struct { Name0 [4]uint8 \"json:\\\"name0\\\"\"; Name1 [2][2]uint8 \"json:\\\"name1\\\"\" }",
+		want: []struct {
+			Name0 [4]byte    `json:"name0"`
+			Name1 [2][2]byte `json:"name1"`
+		}{{[4]byte{0xa9, 0x05, 0x9c, 0xbb}, [2][2]byte{{1, 2}, {3, 4}}}},
+	},
+	{
+		name:  "error: bytes4 too short",
+		blob:  "bytes4[]",
+		value: "[0xa9059c]",
+	},
+	{
+		name:  "error: bytes4 too long",
+		blob:  "bytes4",
+		value: "0xa9059cbb00",
+	},
+	{
+		name:  "error: bytes32 too short",
+		blob:  "bytes32",
+		value: "0x01",
+	},
+	{
+		name:       "normal: function with selector",
+		blob:       "function",
+		value:      "0x00000000006c3852cbef3e08e8df289169ede581:0xa9059cbb",
+		goArgument: "[24]uint8",
+		want:       funcVal,
+	},
+	{
+		name:       "normal: function with signature",
+		blob:       "function",
+		value:      "0x00000000006c3852cbef3e08e8df289169ede581.transfer(address to, uint256 amount)",
+		goArgument: "[24]uint8",
+		want:       funcVal,
+	},
+	{
+		name:       "normal: function raw hex",
+		blob:       "function",
+		value:      "0x00000000006c3852cbef3e08e8df289169ede581a9059cbb",
+		goArgument: "[24]uint8",
+		want:       funcVal,
+	},
+	{
+		name:       "normal: function[] mixed notation",
+		blob:       "function[]",
+		value:      "[0x00000000006c3852cbef3e08e8df289169ede581.transfer(address,uint256), 0x00000000006c3852cbef3e08e8df289169ede581:0xa9059cbb, 0x00000000006c3852cbef3e08e8df289169ede581a9059cbb0000000000000000]",
+		goArgument: "[][24]uint8",
+		want:       [][24]byte{funcVal, funcVal, funcVal},
+	},
+	{
+		name:  "error: function too short",
+		blob:  "function",
+		value: "0x00000000006c3852cbef3e08e8df289169ede581",
+	},
+	{
+		name:  "error: function bad selector",
+		blob:  "function",
+		value: "0x00000000006c3852cbef3e08e8df289169ede581:0xa9059c",
+	},
+	{
+		name:  "error: function bad address",
+		blob:  "function",
+		value: "0x006c3852cbef3e08e8df289169ede581.transfer(address,uint256)",
+	},
+	{
+		name:  "error: function unpaired signature",
+		blob:  "function[]",
+		value: "[0x00000000006c3852cbef3e08e8df289169ede581.transfer(address,uint256]",
+	},
+	{
+		name:       "normal: fixed128x18",
+		blob:       "fixed128x18",
+		value:      "3.14159",
+		goArgument: "*big.Int",
+		want:       big.NewInt(3141590000000000000),
+	},
+	{
+		name:       "normal: ufixed8x1 max",
+		blob:       "ufixed8x1",
+		value:      "25.5",
+		goArgument: "uint8",
+		want:       uint8(255),
+	},
+	{
+		name:       "normal: fixed8x1 min",
+		blob:       "fixed8x1",
+		value:      "-12.8",
+		goArgument: "int8",
+		want:       int8(-128),
+	},
+	{
+		name:       "normal: fixed slice",
+		blob:       "fixed[]",
+		value:      "[1.5, -0.000000000000000001, 2e-3, 7]",
+		goArgument: "[]*big.Int",
+		want:       []*big.Int{big.NewInt(1500000000000000000), big.NewInt(-1), big.NewInt(2000000000000000), big.NewInt(7000000000000000000)},
+	},
+	{
+		name:       "normal: fixed in tuple",
+		blob:       "(ufixed64x10 rate,int8 n)",
+		value:      "(0.0000000001,-1)",
+		goArgument: "struct { Rate uint64 \"json:\\\"rate\\\"\"; N int8 \"json:\\\"n\\\"\" }",
+		want: struct {
+			Rate uint64 `json:"rate"`
+			N    int8   `json:"n"`
+		}{1, -1},
+	},
+	{
+		name:  "error: ufixed8x1 overflow",
+		blob:  "ufixed8x1",
+		value: "25.6",
+	},
+	{
+		name:  "error: ufixed negative",
+		blob:  "ufixed",
+		value: "-1",
+	},
+	{
+		name:  "error: fixed precision",
+		blob:  "fixed128x18",
+		value: "1.0000000000000000001",
+	},
+	{
+		name:  "error: fixed hex",
+		blob:  "fixed128x18",
+		value: "0x10",
+	},
+	{
+		name:  "error: fixed bad size",
+		blob:  "fixed7x1",
+		value: "1",
+	},
+	{
+		name:  "error: fixed bad decimals",
+		blob:  "fixed8x81",
+		value: "1",
+	},
+	{
+		name:  "error: mixed array and scalar",
+		blob:  "uint8[][]",
+		value: "[[1],2]",
+	},
+	{
+		name:  "error: array size mismatch",
+		blob:  "uint8[2]",
+		value: "[1,2,3]",
+	},
 }
 
 func TestAbiParam_Parse(t *testing.T) {
+	for _, tt := range parseTests {
+		t.Run(tt.name, func(t *testing.T) {
+			wantErr := strings.HasPrefix(tt.name, "error:")
+			param, err := NewAbiParam(tt.blob, tt.value)
+			if err != nil {
+				if !wantErr {
+					t.Errorf("new abi param error: %s", err)
+				}
+				return
+			}
+			parsedData, err := param.Parse()
+			if wantErr {
+				if err == nil {
+					t.Errorf("want error, got %v", parsedData)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("parsed abi params error: %s", err)
+				return
+			}
+			t.Logf("res: %v", parsedData)
+			assert.Equal(t, reflect.TypeOf(parsedData).String(), tt.goArgument, "argument: %s", tt.goArgument)
+			assert.Equal(t, parsedData, tt.want, fmt.Sprintf("parsed data: %v", tt.want))
+		})
+	}
+}
+
+func TestParseValueTree(t *testing.T) {
 	tests := []struct {
-		name       string
-		blob       string
-		value      string
-		goArgument string
-		want       interface{}
+		name  string
+		value string
+		want  bool
 	}{
 		{
-			name:       "error: abi unsupported int",
-			blob:       "int",
-			goArgument: "error",
-			value:      "1000",
-		},
-		{
-			name:       "normal: int8",
-			blob:       "int8",
-			value:      "3",
-			goArgument: "int8",
-			want:       int8(3),
-		},
-		{
-			name:       "normal: int16",
-			blob:       "int16",
-			value:      "11",
-			goArgument: "int16",
-			want:       int16(11),
-		},
-		{
-			name:       "normal: int32",
-			blob:       "int32",
-			value:      "1122",
-			goArgument: "int32",
-			want:       int32(1122),
-		},
-		{
-			name:       "normal: int64",
-			blob:       "int64",
-			value:      "111111",
-			goArgument: "int64",
-			want:       int64(111111),
+			name:  "normal:not contains block",
+			value: "1,2,3",
+			want:  true,
 		},
 		{
-			name:       "normal: int128",
-			blob:       "int128",
-			value:      "1000",
-			goArgument: "*big.Int",
-			want:       biVal,
+			name:  "normal:not contains block",
+			value: "[1,2,3]",
+			want:  true,
 		},
 		{
-			name:       "normal: int256",
-			blob:       "int256",
-			value:      "1000",
-			goArgument: "*big.Int",
-			want:       biVal,
+			name:  "normal:not contains block",
+			value: `["1","2","3"]`,
+			want:  true,
 		},
 		{
-			name:       "normal: uint8",
-			blob:       "uint8",
-			value:      "3",
-			goArgument: "uint8",
-			want:       uint8(3),
+			name:  "normal:not contains block",
+			value: `0x543a5aed5abc902553a92547701ac38f73a70785,0x7d2768de32b0b80b7a3454c06bdac94a69ddc7a9,0x028171bCA77440897B824Ca71D1c56caC55b68A3`,
+			want:  true,
 		},
 		{
-			name:       "normal: uint16",
-			blob:       "uint16",
-			value:      "20",
-			goArgument: "uint16",
-			want:       uint16(20),
+			name:  "normal:not contains block",
+			value: `["0x543a5aed5abc902553a92547701ac38f73a70785","0x7d2768de32b0b80b7a3454c06bdac94a69ddc7a9","0x028171bCA77440897B824Ca71D1c56caC55b68A3"]`,
+			want:  true,
 		},
 		{
-			name:       "normal: uint32",
-			blob:       "uint32",
-			value:      "100",
-			goArgument: "uint32",
-			want:       uint32(100),
+			name:  "normal: paired block",
+			value: "[1]",
+			want:  true,
 		},
 		{
-			name:       "normal: uint64",
-			blob:       "uint64",
-			value:      "100",
-			goArgument: "uint64",
-			want:       uint64(100),
+			name:  "normal: paired block",
+			value: "[1,2]",
+			want:  true,
 		},
 		{
-			name:       "normal: uint128",
-			blob:       "uint128",
-			value:      "1000",
-			goArgument: "*big.Int",
-			want:       biVal,
+			name:  "normal: paired block",
+			value: "[[20],[21,23]]",
+			want:  true,
 		},
 		{
-			name:       "normal: uint256",
-			blob:       "uint256",
-			value:      "1000",
-			goArgument: "*big.Int",
-			want:       biVal,
+			name:  "normal: nest array",
+			value: "[[[1,2],[3,4]],[[5,6],[7,8]]]",
+			want:  true,
 		},
 		{
-			name:       "error: uint, abi unSupported",
-			blob:       "uint",
-			value:      "1000",
-			goArgument: "uint",
+			name:  "normal: nest array",
+			value: "[[[[1]]]]",
+			want:  true,
 		},
 		{
-			name:       "normal: address",
-			blob:       "address",
-			value:      "0x00000000006c3852cbef3e08e8df289169ede581",
-			goArgument: "common.Address",
-			want:       common.HexToAddress("0x00000000006c3852cbef3e08e8df289169ede581"),
+			name:  "normal: nest array",
+			value: "[[[[1,2],[1,2]],[[1,2],[3,4]],[[5,6],[7,8]]]]",
+			want:  true,
 		},
 		{
-			name:       "normal: address[]",
-			blob:       "address[]",
-			value:      `["0x00000000006c3852cbef3e08e8df289169ede581","0x1b2667862b2a4f46DfD6C53f561C58a8B0EED0D6"]`,
-			goArgument: "[]common.Address",
-			want: []common.Address{common.HexToAddress("0x00000000006c3852cbef3e08e8df289169ede581"),
-				common.HexToAddress("0x1b2667862b2a4f46DfD6C53f561C58a8B0EED0D6")},
+			name:  "normal: empty array",
+			value: "[[],[]]",
+			want:  true,
 		},
 		{
-			name:       "error: unsupported int[] type",
-			blob:       "int[]",
-			value:      "[1,2]",
-			goArgument: "int[]",
+			name:  "normal: array and scalar",
+			value: "[[1],2]",
+			want:  true,
 		},
 		{
-			name:       "normal: int8[]",
-			blob:       "int8[]",
-			value:      "[1,3]",
-			goArgument: "[]int8",
-			want:       []int8{1, 3},
+			name:  "normal: tuple",
+			value: "[(0x01,[1,2]),(0x02,[])]",
+			want:  true,
 		},
 		{
-			name:       "normal: int16[]",
-			blob:       "int16[]",
-			value:      "[3,233]",
-			goArgument: "[]int16",
-			want:       []int16{3, 233},
+			name:  "normal: quoted string with delimiters",
+			value: `["a,b","[c]","say \"hi\""]`,
+			want:  true,
 		},
 		{
-			name:       "normal: int32[]",
-			blob:       "int32[]",
-			value:      "3,344",
-			goArgument: "[]int32",
-			want:       []int32{3, 344},
+			name:  "error: unterminated string",
+			value: `["1]`,
+			want:  false,
 		},
 		{
-			name:       "normal: int64[]",
-			blob:       "int64[]",
-			value:      "1000,10001",
-			goArgument: "[]int64",
-			want:       []int64{1000, 10001},
+			name:  "error: quote inside word",
+			value: `[[20"],["21,23"]]`,
+			want:  false,
 		},
 		{
-			name:       "normal: int128[]",
-			blob:       "int128[]",
-			value:      "[1000]",
-			goArgument: "[]*big.Int",
-			want:       []*big.Int{biVal},
+			name:  "error: quote repetition",
+			value: `[[20"],["21,23""]]`,
+			want:  false,
 		},
 		{
-			name:       "normal: int256[]",
-			blob:       "int256[]",
-			value:      "[1000]",
-			goArgument: "[]*big.Int",
-			want:       []*big.Int{biVal},
+			name:  "error: unpaired block",
+			value: "[[[1,2]]",
+			want:  false,
 		},
 		{
-			name:       "error: unSupported uint[] type",
-			blob:       "uint[]",
-			value:      "",
-			goArgument: "uint64",
+			name:  "error: unpaired block",
+			value: "[[1,2]]]",
+			want:  false,
 		},
 		{
-			name:       "normal: uint8[]",
-			blob:       "uint8[]",
-			value:      "[1,2,3]",
-			goArgument: "[]uint8",
-			want:       []uint8{1, 2, 3},
+			name:  "error: mismatched block",
+			value: "[(1,2])",
+			want:  false,
 		},
 		{
-			name:       "normal: uint16[]",
-			blob:       "uint16[]",
-			value:      "[4,5,6]",
-			goArgument: "[]uint16",
-			want:       []uint16{4, 5, 6},
+			name:  "error: empty element",
+			value: "[1,,2]",
+			want:  false,
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := parseValueTree(tt.value, Limits{})
+			if (err == nil) != tt.want {
+				t.Errorf("value %s, want ok %v, got err: %v", tt.value, tt.want, err)
+				return
+			}
+			t.Logf("tree: %v", tree)
+		})
+	}
+}
+
+func TestParseValueTree_Offset(t *testing.T) {
+	tree, err := parseValueTree(`[1, "a,b", (x, [2])]`, Limits{})
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	root := tree[0]
+	assert.Equal(t, root.kind, nodeList)
+	assert.Equal(t, len(root.elems), 3)
+	assert.Equal(t, root.elems[0].pos, 1)
+	assert.Equal(t, root.elems[1].kind, nodeString)
+	assert.Equal(t, root.elems[1].text, "a,b")
+	assert.Equal(t, root.elems[1].pos, 4)
+	assert.Equal(t, root.elems[2].kind, nodeTuple)
+	assert.Equal(t, root.elems[2].pos, 11)
+	assert.Equal(t, root.elems[2].elems[1].elems[0].pos, 16)
+}
+
+func TestAbiParam_ParseTuple(t *testing.T) {
+	tests := []struct {
+		name  string
+		blob  string
+		value string
+		want  string
+		err   bool
+	}{
 		{
-			name:       "normal: uint32[]",
-			blob:       "uint32[]",
-			value:      "[100,200,400]",
-			goArgument: "[]uint32",
-			want:       []uint32{100, 200, 400},
+			name:  "normal: tuple",
+			blob:  "(address,uint256)",
+			value: "(0x00000000006c3852cbef3e08e8df289169ede581,100)",
+			want:  "{0x00000000006c3852cbEf3e08E8dF289169EdE581 100}",
 		},
 		{
-			name:       "normal: uint64[]",
-			blob:       "uint64[]",
-			value:      "[1000,2000]",
-			goArgument: "[]uint64",
-			want:       []uint64{1000, 2000},
+			name:  "normal: tuple without parentheses",
+			blob:  "(address,uint256)",
+			value: "0x00000000006c3852cbef3e08e8df289169ede581,100",
+			want:  "{0x00000000006c3852cbEf3e08E8dF289169EdE581 100}",
 		},
 		{
-			name:       "normal: uint128[]",
-			blob:       "uint128[]",
-			value:      "1000",
-			goArgument: "[]*big.Int",
-			want:       []*big.Int{biVal},
+			name:  "normal: named tuple",
+			blob:  "(address to, uint256 amount)",
+			value: "(0x00000000006c3852cbef3e08e8df289169ede581, 1e18)",
+			want:  "{0x00000000006c3852cbEf3e08E8dF289169EdE581 1000000000000000000}",
 		},
 		{
-			name:       "normal: uint256[]",
-			blob:       "uint256[]",
-			value:      "1000",
-			goArgument: "[]*big.Int",
-			want:       []*big.Int{biVal},
+			name:  "normal: tuple slice",
+			blob:  "(address,uint256)[]",
+			value: "[(0x00000000006c3852cbef3e08e8df289169ede581,1),(0x1b2667862b2a4f46DfD6C53f561C58a8B0EED0D6,2)]",
+			want:  "[{0x00000000006c3852cbEf3e08E8dF289169EdE581 1} {0x1b2667862b2a4f46DfD6C53f561C58a8B0EED0D6 2}]",
 		},
 		{
-			name:       "normal: bytes",
-			blob:       "bytes",
-			value:      "0x9e99847ecf80af04f0808e017172bc71b71a5d1bb7b82ab1ce4b2ec666f009425419ad6e1d42c27f0d8408976e20276e5fd2411c6dc42d06d885b4c25d71fbb31c",
-			goArgument: "[]uint8",
-			want:       bytesVal,
+			name:  "normal: nested tuple",
+			blob:  "((uint8,bytes32),string)",
+			value: "((1,0x0000007b02230091a7ed01230072f7006a004d60a8d4e71d599b8104250f0000),abc)",
+			want:  fmt.Sprintf("{{1 %v} abc}", byte32Val),
 		},
 		{
-			name:       "normal: slice bytes",
-			blob:       "bytes[]",
-			value:      "[0x9e99847ecf80af04f0808e017172bc71b71a5d1bb7b82ab1ce4b2ec666f009425419ad6e1d42c27f0d8408976e20276e5fd2411c6dc42d06d885b4c25d71fbb31c,0x9e99847ecf80af04f0808e017172bc71b71a5d1bb7b82ab1ce4b2ec666f009425419ad6e1d42c27f0d8408976e20276e5fd2411c6dc42d06d885b4c25d71fbb31c]",
-			goArgument: "[][]uint8",
-			want:       [][]uint8{bytesVal, bytesVal},
+			name:  "normal: tuple with arrays",
+			blob:  "(uint8[],bool[2])[2]",
+			value: "[([1,2],[true,false]),([],[false,true])]",
+			want:  "[{[1 2] [true false]} {[] [false true]}]",
 		},
 		{
-			name:       "normal: bytes32",
-			blob:       "bytes32",
-			value:      "0x0000007b02230091a7ed01230072f7006a004d60a8d4e71d599b8104250f0000",
-			goArgument: "[32]uint8",
-			want:       byte32Val,
+			name:  "error: tuple field count",
+			blob:  "(address,uint256)",
+			value: "(0x00000000006c3852cbef3e08e8df289169ede581)",
+			err:   true,
 		},
 		{
-			name:       "normal: bytes32[]",
-			blob:       "bytes32[]",
-			value:      "[0x0000007b02230091a7ed01230072f7006a004d60a8d4e71d599b8104250f0000,0x0000007b02230091a7ed01230072f7006a004d60a8d4e71d599b8104250f0000]",
-			goArgument: "[][32]uint8",
-			want:       [][32]byte{byte32Val, byte32Val},
+			name:  "error: tuple array length",
+			blob:  "(uint8,bool)[2]",
+			value: "[(1,true)]",
+			err:   true,
 		},
 		{
-			name:       "normal: string",
-			blob:       "string",
-			value:      "abcd434d32",
-			goArgument: "string",
-			want:       "abcd434d32",
+			name:  "error: unpaired parentheses",
+			blob:  "(uint8,bool)",
+			value: "(1,true",
+			err:   true,
 		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			param, err := NewAbiParam(tt.blob, tt.value)
+			if err != nil {
+				t.Fatalf("new abi param error: %s", err)
+			}
+			parsedData, err := param.Parse()
+			if tt.err {
+				if err == nil {
+					t.Errorf("want error, got %v", parsedData)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsed abi params error: %s", err)
+			}
+			assert.Equal(t, fmt.Sprintf("%v", parsedData), tt.want)
+
+			// 解析结果可以直接交给 abi.Arguments.Pack
+			typ, err := newType(tt.blob)
+			if err != nil {
+				t.Fatalf("new type error: %s", err)
+			}
+			if _, err := (abi.Arguments{{Type: typ.Type}}).Pack(parsedData); err != nil {
+				t.Errorf("pack error: %s", err)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		blob  string
+		value interface{}
+		want  string
+	}{
 		{
-			name:       "normal: bool",
-			blob:       "bool",
-			value:      "1",
-			goArgument: "bool",
-			want:       true,
+			name:  "normal: nested bool",
+			blob:  "bool[2][]",
+			value: [][2]bool{{true, false}, {false, true}},
+			want:  "[[true,false],[false,true]]",
 		},
 		{
-			name:       "normal: bool",
-			blob:       "bool",
-			value:      "true",
-			goArgument: "bool",
-			want:       true,
+			name:  "normal: big int",
+			blob:  "int256",
+			value: big.NewInt(-1000),
+			want:  "-1000",
 		},
 		{
-			name:       "normal: array",
-			blob:       "address[3]",
-			value:      `0x543a5aed5abc902553a92547701ac38f73a70785,0x7d2768de32b0b80b7a3454c06bdac94a69ddc7a9,0x028171bCA77440897B824Ca71D1c56caC55b68A3`,
-			goArgument: "[3]common.Address",
-			want: [3]common.Address{common.HexToAddress("0x543a5aed5abc902553a92547701ac38f73a70785"),
-				common.HexToAddress("0x7d2768de32b0b80b7a3454c06bdac94a69ddc7a9"),
-				common.HexToAddress("0x028171bCA77440897B824Ca71D1c56caC55b68A3")},
+			name:  "normal: address",
+			blob:  "address",
+			value: common.HexToAddress("0x1b2667862b2a4f46dfd6c53f561c58a8b0eed0d6"),
+			want:  "0x1b2667862b2a4f46DfD6C53f561C58a8B0EED0D6",
 		},
 		{
-			name:       "normal: new fmt array",
-			blob:       "address[3]",
-			value:      `[0x543a5aed5abc902553a92547701ac38f73a70785,0x7d2768de32b0b80b7a3454c06bdac94a69ddc7a9,0x028171bCA77440897B824Ca71D1c56caC55b68A3]`,
-			goArgument: "[3]common.Address",
-			want: [3]common.Address{common.HexToAddress("0x543a5aed5abc902553a92547701ac38f73a70785"),
-				common.HexToAddress("0x7d2768de32b0b80b7a3454c06bdac94a69ddc7a9"),
-				common.HexToAddress("0x028171bCA77440897B824Ca71D1c56caC55b68A3")},
+			name:  "normal: bytes32",
+			blob:  "bytes32",
+			value: byte32Val,
+			want:  "0x0000007b02230091a7ed01230072f7006a004d60a8d4e71d599b8104250f0000",
 		},
 		{
-			name:       "normal: slice && array with bool",
-			blob:       "bool[][2]",
-			value:      "[[1,0,1],[0,1]]",
-			goArgument: "[2][]bool",
-			want:       [2][]bool{{true, false, true}, {false, true}},
+			name:  "normal: quoted string",
+			blob:  "string[]",
+			value: []string{"a,b", "", `say "hi"`, "abc"},
+			want:  `["a,b","","say \"hi\"",abc]`,
 		},
 		{
-			name:       "normal: slice && array with bool",
-			blob:       "bool[2][2]",
-			value:      "[[true,false],[false,true]]",
-			goArgument: "[2][2]bool",
-			want:       [2][2]bool{{true, false}, {false, true}},
+			name: "normal: tuple",
+			blob: "(uint8,string)",
+			value: struct {
+				A uint8
+				B string
+			}{1, "x"},
+			want: "(1,x)",
 		},
 		{
-			name:       "normal: slice && array with string",
-			blob:       "string[][3]",
-			value:      `[[aaa,vvv,bbb],[w4f,6s%#],[14c14,c423,f34e&*^,fjhvfw]]`,
-			goArgument: "[3][]string",
-			want:       [3][]string{{"aaa", "vvv", "bbb"}, {"w4f", "6s%#"}, {"14c14", "c423", "f34e&*^", "fjhvfw"}},
+			name:  "error: type mismatch",
+			blob:  "uint8[]",
+			value: []string{"1"},
 		},
 		{
-			name:  "error: slice && array, out of index",
-			blob:  "bool[][2]",
-			value: "[[1,0],[0,0,1]]",
-			want:  false,
+			name:  "error: array length",
+			blob:  "bool[2]",
+			value: []bool{true},
 		},
 		{
-			name:  "error: slice && array, arguments",
-			blob:  "bool[][2]",
-			value: "[[1,5],[2,4]]]",
-			want:  false,
+			name:  "error: nil interface element",
+			blob:  "uint8[]",
+			value: []interface{}{nil},
 		},
 		{
-			name:       "nested array",
-			blob:       "int8[2][2][2]",
-			value:      "[[[1,2],[3,4]],[[5,6],[7,8]]]",
-			goArgument: "[2][2][2]int8",
-			want:       [2][2][2]int8{{{1, 2}, {3, 4}}, {{5, 6}, {7, 8}}},
+			name:  "error: nil tuple pointer",
+			blob:  "(uint8)",
+			value: (*struct{ A uint8 })(nil),
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			param, err := NewAbiParam(tt.blob, tt.value)
+			typ, err := newType(tt.blob)
 			if err != nil {
-				t.Errorf("new abi param error: %s", err)
+				t.Fatalf("new type error: %s", err)
+			}
+			got, err := format(typ.fixed, &typ.Type, tt.value)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("want error, got %s", got)
+				}
+				if errors.Is(err, errPanic) {
+					t.Errorf("want format error, got %s", err)
+				}
 				return
 			}
+			if err != nil {
+				t.Fatalf("format error: %s", err)
+			}
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
+
+// Parse(Format(v)) == v
+func TestFormat_RoundTrip(t *testing.T) {
+	// 空字符串不能作为 parseTests 的 value，只在这里检查
+	tests := append([]parseTest{
+		{name: "empty string", blob: "string", want: ""},
+		{name: "empty string in array", blob: "string[]", want: []string{"", "a"}},
+	}, parseTests...)
+	for _, tt := range tests {
+		if strings.HasPrefix(tt.name, "error:") {
+			continue
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			typ, err := newType(tt.blob)
+			if err != nil {
+				t.Fatalf("new type error: %s", err)
+			}
+			formatted, err := format(typ.fixed, &typ.Type, tt.want)
+			if err != nil {
+				t.Fatalf("format error: %s", err)
+			}
+			param, err := NewAbiParam(tt.blob, formatted)
+			if err != nil {
+				t.Fatalf("new abi param error: %s", err)
+			}
 			parsedData, err := param.Parse()
 			if err != nil {
-				t.Errorf("parsed abi params error: %s", err)
+				t.Fatalf("parse %s error: %s", formatted, err)
+			}
+			assert.Equal(t, parsedData, tt.want, fmt.Sprintf("formatted: %s", formatted))
+		})
+	}
+}
+
+func TestReadInteger_RangeError(t *testing.T) {
+	var pe *ParseError
+	typ, _ := newType("uint8")
+	_, err := readInteger(typ.Type, "300", 0, defaultUnits)
+	if !errors.As(err, &pe) {
+		t.Fatalf("want *ParseError, got %v", err)
+	}
+	assert.Equal(t, pe.Kind, ErrRange)
+	assert.Equal(t, pe.Err.Error(), "abi: value 300 overflows uint8, max is 255")
+
+	typ, _ = newType("int128")
+	_, err = readInteger(typ.Type, "-170141183460469231731687303715884105729", 0, defaultUnits)
+	if !errors.As(err, &pe) {
+		t.Fatalf("want *ParseError, got %v", err)
+	}
+	assert.Equal(t, pe.Err.Error(), "abi: value -170141183460469231731687303715884105729 underflows int128, min is -170141183460469231731687303715884105728")
+}
+
+func TestAbiParam_ParseAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		blob     string
+		value    string
+		decimals int
+		want     string
+	}{
+		{name: "normal: ether", blob: "uint256", value: "1.5 ether", want: "1500000000000000000"},
+		{name: "normal: ether without space", blob: "uint256", value: "1.5ether", want: "1500000000000000000"},
+		{name: "normal: gwei", blob: "uint256", value: "20 gwei", want: "20000000000"},
+		{name: "normal: wei", blob: "uint256", value: "3 wei", want: "3"},
+		{name: "normal: upper case unit", blob: "uint256", value: "2 GWEI", want: "2000000000"},
+		{name: "normal: negative ether", blob: "int256", value: "-0.25 ether", want: "-250000000000000000"},
+		{name: "normal: decimals", blob: "uint256", value: "123.45", decimals: 6, want: "123450000"},
+		{name: "normal: decimals integer", blob: "uint256", value: "100", decimals: 6, want: "100000000"},
+		{name: "normal: decimals trailing zero", blob: "uint256", value: "1.1000000", decimals: 6, want: "1100000"},
+		{name: "normal: decimals hex is raw", blob: "uint256", value: "0x10", decimals: 6, want: "16"},
+		{name: "normal: unit overrides decimals", blob: "uint256", value: "1 gwei", decimals: 6, want: "1000000000"},
+		{name: "normal: decimals in array", blob: "uint64[]", value: "[1.5,2]", decimals: 2, want: "[150 200]"},
+		{name: "normal: plain integer", blob: "uint256", value: "1000", want: "1000"},
+		{name: "normal: scientific", blob: "uint256", value: "1e18", want: "1000000000000000000"},
+		{name: "normal: exact scientific", blob: "uint256", value: "1.234567890123456789e30", want: "1234567890123456789000000000000"},
+		{name: "normal: negative exponent", blob: "uint8", value: "2550e-1", want: "255"},
+		{name: "normal: negative scientific", blob: "int64", value: "-1.5E3", want: "-1500"},
+		{name: "normal: scientific with unit", blob: "uint256", value: "2.5e-3 ether", want: "2500000000000000"},
+		{name: "normal: scientific with decimals", blob: "uint256", value: "1.5e2", decimals: 6, want: "150000000"},
+		{name: "normal: scientific in array", blob: "uint256[]", value: "[1e3,2.5e1]", want: "[1000 25]"},
+		{name: "normal: string looks like number", blob: "string", value: "1e3", want: "1e3"},
+		{name: "normal: string[] looks like number", blob: "string[]", value: "[1e3,1.5]", want: "[1e3 1.5]"},
+		{name: "error: scientific not integer", blob: "uint256", value: "1.5e-1"},
+		{name: "error: scientific overflow", blob: "uint8", value: "2.56e2"},
+		{name: "error: exponent out of range", blob: "uint256", value: "1e5000"},
+		{name: "error: missing exponent", blob: "uint256", value: "1e"},
+		{name: "error: fraction without decimals", blob: "uint256", value: "1.5"},
+		{name: "error: precision loss", blob: "uint256", value: "1.0000001", decimals: 6},
+		{name: "error: sub wei", blob: "uint256", value: "1.5 wei"},
+		{name: "error: overflow after scaling", blob: "uint64", value: "19 ether"},
+		{name: "error: unknown unit", blob: "uint256", value: "1 foo"},
+		{name: "error: lone dot", blob: "uint256", value: ".", decimals: 6},
+		{name: "error: minus then plus", blob: "int256", value: "-+5"},
+		{name: "error: plus then minus", blob: "int256", value: "+-5"},
+		{name: "error: double sign on uint", blob: "uint256", value: "-+5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			param, err := NewAbiParam(tt.blob, tt.value)
+			if err != nil {
+				t.Fatalf("new abi param error: %s", err)
+			}
+			parsedData, err := param.SetDecimals(tt.decimals).Parse()
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("want error, got %v", parsedData)
+				}
 				return
 			}
-			t.Logf("res: %v", parsedData)
-			assert.Equal(t, reflect.TypeOf(parsedData).String(), tt.goArgument, "argument: %s", tt.goArgument)
-			assert.Equal(t, parsedData, tt.want, fmt.Sprintf("parsed data: %v", tt.want))
+			if err != nil {
+				t.Fatalf("parsed abi params error: %s", err)
+			}
+			assert.Equal(t, fmt.Sprintf("%v", parsedData), tt.want)
 		})
 	}
 }
 
-func TestParseUnpackString(t *testing.T) {
+func TestAbiParam_ParseError(t *testing.T) {
 	tests := []struct {
-		name  string
-		value string
-		want  bool
+		name   string
+		blob   string
+		value  string
+		kind   ErrorKind
+		typ    string
+		path   string
+		offset int
+		token  string
 	}{
 		{
-			name:  "normal:not contains block",
-			value: "1,2,3",
-			want:  false,
+			name:   "bad bool in nested array",
+			blob:   "bool[][2]",
+			value:  "[[1,0],[5,1]]",
+			kind:   ErrInvalid,
+			typ:    "bool",
+			path:   "[1][0]",
+			offset: 8,
+			token:  "5",
 		},
 		{
-			name:  "normal:not contains block",
-			value: "[1,2,3]",
-			want:  true,
+			name:   "uint8 overflow",
+			blob:   "uint8[]",
+			value:  "[1, 300]",
+			kind:   ErrRange,
+			typ:    "uint8",
+			path:   "[1]",
+			offset: 4,
+			token:  "300",
 		},
 		{
-			name:  "normal:not contains block",
-			value: `["1","2","3"]`,
-			want:  true,
-		},
-		{
-			name:  "normal:not contains block",
-			value: `0x543a5aed5abc902553a92547701ac38f73a70785,0x7d2768de32b0b80b7a3454c06bdac94a69ddc7a9,0x028171bCA77440897B824Ca71D1c56caC55b68A3`,
-			want:  true,
-		},
-		{
-			name:  "normal:not contains block",
-			value: "0x543a5aed5abc902553a92547701ac38f73a70785,0x7d2768de32b0b80b7a3454c06bdac94a69ddc7a9,0x028171bCA77440897B824Ca71D1c56caC55b68A3",
-			want:  true,
-		},
-		{
-			name:  "normal:not contains block",
-			value: `["0x543a5aed5abc902553a92547701ac38f73a70785","0x7d2768de32b0b80b7a3454c06bdac94a69ddc7a9","0x028171bCA77440897B824Ca71D1c56caC55b68A3"]`,
-			want:  true,
+			name:   "tuple field",
+			blob:   "(address to,uint256 amount)[]",
+			value:  "[(0x00000000006c3852cbef3e08e8df289169ede581,abc)]",
+			kind:   ErrInvalid,
+			typ:    "uint256",
+			path:   "[0].amount",
+			offset: 45,
+			token:  "abc",
 		},
 		{
-			name:  "normal: paired block",
-			value: "[1]",
-			want:  true,
+			name:   "array length",
+			blob:   "uint8[2][]",
+			value:  "[[1,2],[3]]",
+			kind:   ErrLength,
+			typ:    "uint8[2]",
+			path:   "[1]",
+			offset: 7,
 		},
 		{
-			name:  "normal: paired block",
-			value: `["1]`,
-			want:  true,
+			name:   "scalar for array",
+			blob:   "uint8[][]",
+			value:  "[[1],2]",
+			kind:   ErrMismatch,
+			typ:    "uint8[]",
+			path:   "[1]",
+			offset: 5,
+			token:  "2",
 		},
 		{
-			name:  "normal: paired block",
-			value: "[1,2]",
-			want:  true,
+			name:   "unpaired block",
+			blob:   "uint8[]",
+			value:  "[1,2",
+			kind:   ErrSyntax,
+			typ:    "uint8[]",
+			offset: 4,
 		},
 		{
-			name:  "normal: paired block",
-			value: "[[20],[21,23]]",
-			want:  true,
+			name:  "top level scalar",
+			blob:  "bool",
+			value: "yes",
+			kind:  ErrInvalid,
+			typ:   "bool",
+			token: "yes",
 		},
 		{
-			name:  "normal: paired block",
-			value: `[[20"],["21,23"]]`,
-			want:  true,
+			name: "bad type",
+			blob: "uint",
+			kind: ErrType,
+			typ:  "uint",
 		},
 		{
-			name:  "normal: nest array",
-			value: "[[[1,2],[3,4]],[[5,6],[7,8]]]",
-			want:  true,
+			name:   "short fixed bytes",
+			blob:   "(uint8,bytes4[])",
+			value:  "(1,[0xa9059cbb,0x01])",
+			kind:   ErrLength,
+			typ:    "bytes4",
+			path:   ".name1[1]",
+			offset: 15,
+			token:  "0x01",
 		},
 		{
-			name:  "normal: nest array",
-			value: "[[[[1]]]]",
-			want:  true,
-		},
-		{
-			name:  "normal: nest array",
-			value: "[[[[1,2],[1,2]],[[1,2],[3,4]],[[5,6],[7,8]]]]",
-			want:  true,
-		},
-		{
-			name:  "error: quote repetition",
-			value: `[[20"],["21,23""]]`,
-			want:  false,
-		},
-		{
-			name:  "error: unpaired block",
-			value: "[[[1,2]]",
-			want:  false,
-		},
-		{
-			name:  "error: unpaired block",
-			value: "[[1,2]]]",
-			want:  false,
+			name:   "bad fixed bytes",
+			blob:   "bytes32[]",
+			value:  "[0xzz]",
+			kind:   ErrInvalid,
+			typ:    "bytes32",
+			path:   "[0]",
+			offset: 1,
+			token:  "0xzz",
 		},
 	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			param := defaultParser.newParam(tt.blob, tt.value)
+			_, err := param.Parse()
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("want *ParseError, got %v", err)
+			}
+			t.Logf("err: %s", err)
+			assert.Equal(t, pe.Kind, tt.kind)
+			assert.Equal(t, pe.Type, tt.typ)
+			assert.Equal(t, pe.Path, tt.path)
+			assert.Equal(t, pe.Offset, tt.offset)
+			assert.Equal(t, pe.Token, tt.token)
+		})
+	}
+}
+
+func TestAbiParam_ParseInto(t *testing.T) {
+	type order struct {
+		Maker  common.Address `abi:"maker"`
+		Amount uint64         `abi:"amount"`
+		Flags  []bool
+	}
+	param, _ := NewAbiParam("(address maker,uint256 amount,bool[] flags)[]", "[(0x00000000006c3852cbef3e08e8df289169ede581,1000,[true]),(0x1b2667862b2a4f46DfD6C53f561C58a8B0EED0D6,2,[])]")
+	var orders []order
+	if err := param.ParseInto(&orders); err != nil {
+		t.Fatalf("parse into error: %s", err)
+	}
+	assert.Equal(t, orders, []order{
+		{Maker: common.HexToAddress("0x00000000006c3852cbef3e08e8df289169ede581"), Amount: 1000, Flags: []bool{true}},
+		{Maker: common.HexToAddress("0x1b2667862b2a4f46DfD6C53f561C58a8B0EED0D6"), Amount: 2, Flags: []bool{}},
+	})
+
+	// 成员超出目标字段范围时指出字段路径
+	param, _ = NewAbiParam("(address maker,uint256 amount,bool[] flags)[]", "[(0x00000000006c3852cbef3e08e8df289169ede581,1e20,[])]")
+	err := param.ParseInto(&orders)
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("want *ParseError, got %v", err)
+	}
+	assert.Equal(t, pe.Kind, ErrRange)
+	assert.Equal(t, pe.Path, "[0].amount")
+
+	type missing struct {
+		Maker common.Address
+	}
+	var m missing
+	param, _ = NewAbiParam("(address maker,uint256 amount)", "(0x00000000006c3852cbef3e08e8df289169ede581,1)")
+	if err := param.ParseInto(&m); err == nil {
+		t.Errorf("want missing field error")
+	}
+	if err := param.ParseInto(m); err == nil {
+		t.Errorf("want non-pointer error")
+	}
+}
+
+func TestParseAs(t *testing.T) {
+	bools, err := ParseAs[[2][]bool]("bool[][2]", "[[1,0,1],[0,1]]")
+	if err != nil {
+		t.Fatalf("parse as error: %s", err)
+	}
+	assert.Equal(t, bools, [2][]bool{{true, false, true}, {false, true}})
+
+	amounts, err := ParseAs[[]uint64]("uint256[]", "[1,2e3]")
+	if err != nil {
+		t.Fatalf("parse as error: %s", err)
+	}
+	assert.Equal(t, amounts, []uint64{1, 2000})
+
+	small, err := ParseAs[[]*big.Int]("uint8[]", "[1,2]")
+	if err != nil {
+		t.Fatalf("parse as error: %s", err)
+	}
+	assert.Equal(t, small, []*big.Int{big.NewInt(1), big.NewInt(2)})
+
+	ptr, err := ParseAs[*uint32]("uint256", "7")
+	if err != nil {
+		t.Fatalf("parse as error: %s", err)
+	}
+	assert.Equal(t, *ptr, uint32(7))
+
+	hash, err := ParseAs[common.Hash]("bytes32", "0x0000007b02230091a7ed01230072f7006a004d60a8d4e71d599b8104250f0000")
+	if err != nil {
+		t.Fatalf("parse as error: %s", err)
+	}
+	assert.Equal(t, hash, common.Hash(byte32Val))
+
+	if _, err := ParseAs[int8]("int16", "200"); err == nil {
+		t.Errorf("want overflow error")
+	}
+	if _, err := ParseAs[string]("uint8", "1"); err == nil {
+		t.Errorf("want type mismatch error")
+	}
+}
+
+func TestAbiParam_ParseJSON(t *testing.T) {
+	big21, _ := new(big.Int).SetString("1000000000000000000000", 10)
+	tests := []struct {
+		name  string
+		blob  string
+		value string
+		want  interface{}
+	}{
+		{"uint256 number", "uint256", `1000000000000000000000`, big21},
+		{"uint256 string", "uint256", `"1.5 gwei"`, big.NewInt(1500000000)},
+		{"bool", "bool", `true`, true},
+		{"string", "string", `"abc"`, "abc"},
+		{"uint8 slice", "uint8[]", `[1, "2", 3e1]`, []uint8{1, 2, 30}},
+		{"empty slice", "uint8[]", `[]`, []uint8{}},
+		{"bool array", "bool[][2]", `[[true,false],[]]`, [2][]bool{{true, false}, {}}},
+		{"tuple object", "(address to,uint256 amount)", `{"amount":"1000","to":"0x00000000006c3852cbef3e08e8df289169ede581"}`, struct {
+			To     common.Address `json:"to"`
+			Amount *big.Int       `json:"amount"`
+		}{common.HexToAddress("0x00000000006c3852cbef3e08e8df289169ede581"), big.NewInt(1000)}},
+		{"tuple array", "(address,uint8)[]", `[["0x00000000006c3852cbef3e08e8df289169ede581",1]]`, []struct {
+			Name0 common.Address `json:"name0"`
+			Name1 uint8          `json:"name1"`
+		}{{common.HexToAddress("0x00000000006c3852cbef3e08e8df289169ede581"), 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			param, err := NewAbiParamJSON(tt.blob, tt.value)
+			if err != nil {
+				t.Fatalf("new abi param error: %s", err)
+			}
+			got, err := param.Parse()
+			if err != nil {
+				t.Fatalf("parse error: %s", err)
+			}
+			assert.Equal(t, got, tt.want)
+		})
+	}
+}
+
+func TestAbiParam_ParseJSONError(t *testing.T) {
+	tests := []struct {
+		name   string
+		blob   string
+		value  string
+		kind   ErrorKind
+		path   string
+		offset int
+	}{
+		{"syntax", "uint8[]", `[1,2`, ErrSyntax, "", 4},
+		{"trailing data", "uint8", `1 2`, ErrSyntax, "", 2},
+		{"null", "uint8[]", `[1, null]`, ErrInvalid, "[1]", 4},
+		{"overflow", "uint8[]", `[1, 256]`, ErrRange, "[1]", 4},
+		{"object for array", "uint8[]", `{"a":1}`, ErrMismatch, "", 0},
+		{"array for scalar", "(uint8 a,bool b)", `{"a":[1],"b":true}`, ErrMismatch, ".a", 5},
+		{"unknown field", "(uint8 a,bool b)", `{"a":1,"c":true}`, ErrMismatch, "", 7},
+		{"missing field", "(uint8 a,bool b)", `{"a":1}`, ErrLength, "", 6},
+		{"array length", "uint8[2]", `[1]`, ErrLength, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			param, _ := NewAbiParamJSON(tt.blob, tt.value)
+			_, err := param.Parse()
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("want *ParseError, got %v", err)
+			}
+			assert.Equal(t, pe.Kind, tt.kind, err.Error())
+			assert.Equal(t, pe.Path, tt.path, err.Error())
+			assert.Equal(t, pe.Offset, tt.offset, err.Error())
+		})
+	}
+}
+
+func TestAbiParam_ParseJSONFixedName(t *testing.T) {
+	tests := []struct {
+		name  string
+		blob  string
+		value string
+		want  string
+	}{
+		{"null", "fixed128x18", `null`, "fixed128x18"},
+		{"array for scalar", "(ufixed8x1 a)", `{"a":[1]}`, "ufixed8x1"},
+		{"object for array", "fixed128x18[]", `{"a":1}`, "fixed128x18[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			param, _ := NewAbiParamJSON(tt.blob, tt.value)
+			_, err := param.Parse()
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("want *ParseError, got %v", err)
+			}
+			assert.Equal(t, pe.Type, tt.want, err.Error())
+		})
+	}
+}
 
+func TestAbiParam_SetPadding(t *testing.T) {
+	tests := []struct {
+		name    string
+		padding Padding
+		blob    string
+		value   string
+		want    interface{}
+	}{
+		{"right", PadRight, "bytes4", "0x12", [4]byte{0x12}},
+		{"left", PadLeft, "bytes4", "0x12", [4]byte{0, 0, 0, 0x12}},
+		{"right nested", PadRight, "bytes2[][1]", "[[0x01,0x0203,0x]]", [1][][2]byte{{{1, 0}, {2, 3}, {0, 0}}}},
+		{"left nested", PadLeft, "bytes2[][1]", "[[0x01,0x0203,0x]]", [1][][2]byte{{{0, 1}, {2, 3}, {0, 0}}}},
+	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			unpackedData, err := parseUnpackString(tt.value)
+			param, _ := NewAbiParam(tt.blob, tt.value)
+			got, err := param.SetPadding(tt.padding).Parse()
 			if err != nil {
-				t.Errorf("err: %s", err)
+				t.Fatalf("parse error: %s", err)
+			}
+			assert.Equal(t, got, tt.want)
+		})
+	}
+
+	// 超长的输入不会被截断
+	param, _ := NewAbiParam("bytes4", "0x1234567890")
+	if _, err := param.SetPadding(PadRight).Parse(); err == nil {
+		t.Errorf("want length error")
+	}
+}
+
+func TestNewType_Fixed(t *testing.T) {
+	tests := []struct {
+		blob string
+		want string
+	}{
+		{"fixed", "fixed128x18"},
+		{"ufixed[2]", "ufixed128x18[2]"},
+		{"fixed64x10[][3]", "fixed64x10[][3]"},
+		{"(fixed a,(ufixed8x1 b,int8 c)[] d)[2]", "(fixed128x18,(ufixed8x1,int8)[])[2]"},
+	}
+	for _, tt := range tests {
+		typ, err := newType(tt.blob)
+		if err != nil {
+			t.Fatalf("new type %s error: %s", tt.blob, err)
+		}
+		assert.Equal(t, typ.String(), tt.want)
+		// go-ethereum 的类型本身不被修改
+		assert.Equal(t, strings.Contains(typ.Type.String(), "fixed"), false)
+	}
+	for _, blob := range []string{"fixed12", "ufixed8x1abc", "fixed7x1", "fixed8x81"} {
+		if _, err := newType(blob); err == nil {
+			t.Errorf("want unsupported type error for %s", blob)
+		}
+	}
+}
+
+func TestAbiParam_SetStrictAddress(t *testing.T) {
+	rsk := big.NewInt(30)
+	tests := []struct {
+		name    string
+		blob    string
+		value   string
+		opts    AddressOptions
+		want    interface{}
+		suggest string // 非空时期望 *ChecksumError
+	}{
+		{name: "normal: checksum", blob: "address", value: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", want: common.HexToAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")},
+		{name: "normal: lower case", blob: "address", value: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", want: common.HexToAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")},
+		{name: "normal: upper case", blob: "address", value: "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", want: common.HexToAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")},
+		{name: "normal: eip-1191", blob: "address", value: "0x5aaEB6053f3e94c9b9a09f33669435E7ef1bEAeD", opts: AddressOptions{ChainID: rsk}, want: common.HexToAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")},
+		{name: "normal: eip-1191 testnet", blob: "address", value: "0xdbF03B407C01E7cd3cbEa99509D93f8dDDc8C6fB", opts: AddressOptions{ChainID: big.NewInt(31)}, want: common.HexToAddress("0xdbf03b407c01e7cd3cbea99509d93f8dddc8c6fb")},
+		{name: "normal: zero allowed", blob: "address", value: "0x0000000000000000000000000000000000000000", opts: AddressOptions{AllowZero: true}, want: common.Address{}},
+		{name: "normal: address slice", blob: "address[]", value: "[0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed]", want: []common.Address{common.HexToAddress("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")}},
+		{name: "error: bad checksum", blob: "address", value: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", suggest: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{name: "error: eip-55 on rsk", blob: "address", value: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", opts: AddressOptions{ChainID: rsk}, suggest: "0x5aaEB6053f3e94c9b9a09f33669435E7ef1bEAeD"},
+		{name: "error: bad checksum in tuple", blob: "(uint8,address)", value: "(1,0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD)", suggest: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{name: "error: junk", blob: "address", value: "hello"},
+		{name: "error: no prefix", blob: "address", value: "5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"},
+		{name: "error: 19 bytes", blob: "address", value: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea"},
+		{name: "error: 21 bytes", blob: "address", value: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed00"},
+		{name: "error: zero address", blob: "address", value: "0x0000000000000000000000000000000000000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			param, _ := NewAbiParam(tt.blob, tt.value)
+			got, err := param.SetStrictAddress(tt.opts).Parse()
+			if !strings.HasPrefix(tt.name, "error:") {
+				if err != nil {
+					t.Fatalf("parse error: %s", err)
+				}
+				assert.Equal(t, got, tt.want)
 				return
 			}
-			t.Logf("unpackedData: %v", unpackedData)
+			if err == nil {
+				t.Fatalf("want error, got %v", got)
+			}
+			var ce *ChecksumError
+			if tt.suggest != "" {
+				if !errors.As(err, &ce) {
+					t.Fatalf("want *ChecksumError, got %v", err)
+				}
+				assert.Equal(t, ce.Suggest, tt.suggest)
+			}
 		})
 	}
+
+	// 默认模式保持原有的宽松行为
+	param, _ := NewAbiParam("address", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea")
+	if _, err := param.Parse(); err != nil {
+		t.Errorf("lenient parse error: %s", err)
+	}
+}
+
+func TestParser(t *testing.T) {
+	book := NewAddressBook().Add("usdc", common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"))
+	p := NewParser(
+		WithDecimals(6),
+		WithUnits(map[string]int{"USDC": 6}),
+		WithStrict(),
+		WithResolver(book),
+		WithMaxDepth(2),
+		WithPadding(PadRight),
+	)
+
+	tests := []struct {
+		name  string
+		blob  string
+		value string
+		want  interface{}
+	}{
+		{"decimals", "uint256", "1.5", big.NewInt(1500000)},
+		{"custom unit", "uint64[]", "[1.5 usdc, 2gwei]", []uint64{1500000, 2000000000}},
+		{"resolver", "address", "@usdc", common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")},
+		{"padding", "bytes4", "0x12", [4]byte{0x12}},
+		{"depth", "bool[][]", "[[1],[0]]", [][]bool{{true}, {false}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.Parse(tt.blob, tt.value)
+			if err != nil {
+				t.Fatalf("parse error: %s", err)
+			}
+			assert.Equal(t, got, tt.want)
+		})
+	}
+
+	if _, err := p.Parse("address", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD"); err == nil {
+		t.Errorf("want checksum error")
+	}
+	var pe *ParseError
+	_, err := p.Parse("uint8[][][]", "[[[1]]]")
+	if !errors.As(err, &pe) || pe.Kind != ErrLimit {
+		t.Errorf("want depth error, got %v", err)
+	}
+	_, err = p.ParseJSON("uint8[][][]", "[[[1]]]")
+	if !errors.As(err, &pe) || pe.Kind != ErrLimit {
+		t.Errorf("want json depth error, got %v", err)
+	}
+	if _, err := p.Parse("uint256", ""); err != errBadValue {
+		t.Errorf("want errBadValue, got %v", err)
+	}
+
+	// 选项不影响默认的 NewAbiParam
+	param, _ := NewAbiParam("uint256", "1.5")
+	if _, err := param.Parse(); err == nil {
+		t.Errorf("want precision error without decimals")
+	}
+}
+
+func TestParser_Concurrent(t *testing.T) {
+	p := NewParser()
+	blob := "(address to,uint256[] amounts)[]"
+	value := "[(0x00000000006c3852cbef3e08e8df289169ede581,[1,2]),(0x1b2667862b2a4f46DfD6C53f561C58a8B0EED0D6,[3])]"
+	want, err := p.Parse(blob, value)
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	if _, ok := typeCache.get(blob); !ok {
+		t.Errorf("type %s is not cached", blob)
+	}
+
+	errs := make(chan error, 32)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			got, err := p.Parse(blob, value)
+			if err == nil && !reflect.DeepEqual(got, want) {
+				err = fmt.Errorf("got %v, want %v", got, want)
+			}
+			errs <- err
+		}()
+	}
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestTypeLRU(t *testing.T) {
+	c := newTypeLRU(2)
+	for _, blob := range []string{"uint8", "bool", "uint8", "address"} {
+		typ, err := newType(blob)
+		if err != nil {
+			t.Fatalf("new type error: %s", err)
+		}
+		if _, ok := c.get(blob); !ok {
+			c.add(blob, typ)
+		}
+	}
+	// bool 最久未使用，被淘汰
+	assert.Equal(t, c.len(), 2)
+	for blob, want := range map[string]bool{"uint8": true, "bool": false, "address": true} {
+		_, ok := c.get(blob)
+		assert.Equal(t, ok, want, blob)
+	}
+
+	// 超长的 blob 不缓存
+	blob := "(" + strings.Repeat("uint8,", maxCachedBlob/6) + "bool)"
+	if _, err := cachedType(blob); err != nil {
+		t.Fatalf("cached type error: %s", err)
+	}
+	if _, ok := typeCache.get(blob); ok {
+		t.Errorf("blob of %d bytes should not be cached", len(blob))
+	}
+}
+
+type recordLogger struct {
+	lines []string
+}
+
+func (l *recordLogger) Debugf(format string, args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func TestParser_Logger(t *testing.T) {
+	rec := &recordLogger{}
+	if _, err := NewParser(WithLogger(LogrusLogger(rec))).Parse("uint8[][]", "[[1,2],[3]]"); err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	assert.Equal(t, rec.lines, []string{"nest type: uint8[]", "nest type: uint8", "nest type: uint8"})
+
+	// typed nil 与 nil 一样不输出
+	for _, l := range []Logger{LogrusLogger(nil), LogrusLogger((*recordLogger)(nil))} {
+		if _, err := NewParser(WithLogger(l)).Parse("uint8[]", "[1]"); err != nil {
+			t.Fatalf("parse error: %s", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	if _, err := NewParser(WithLogger(SlogLogger(logger))).Parse("bool[]", "[1]"); err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	if !strings.Contains(buf.String(), "nest type: bool") {
+		t.Errorf("slog output %q", buf.String())
+	}
+
+	// 默认不输出，nil 等价于不输出
+	if _, err := NewParser(WithLogger(nil)).Parse("bool[]", "[1]"); err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+}
+
+func TestParser_Limits(t *testing.T) {
+	p := NewParser(WithLimits(Limits{MaxInputSize: 64, MaxDepth: 2, MaxArrayLength: 3, MaxBytesLength: 4}))
+
+	tests := []struct {
+		name  string
+		blob  string
+		value string
+		json  bool
+		err   string // 为空时应解析成功
+	}{
+		{"normal: within limits", "(uint8[],bytes,string)", `([1,2,3],0x01020304,"abcd")`, false, ""},
+		{"error: input size", "string", strings.Repeat("a", 60), false, "input is 66 bytes, limit is 64"},
+		{"error: depth", "uint8[][][]", "[[[1]]]", false, "nesting deeper than 2"},
+		{"error: json depth", "uint8[][][]", "[[[1]]]", true, "nesting deeper than 2"},
+		{"error: array length", "uint8[]", "[1,2,3,4]", false, "more than 3 elements"},
+		{"error: array length without brackets", "uint8[]", "1,2,3,4", false, "more than 3 elements"},
+		{"error: json array length", "uint8[]", "[1,2,3,4]", true, "more than 3 elements"},
+		{"error: fixed array length", "(bool,uint8[4])", "(true,[1])", false, "(bool,uint8[4]) has a fixed array of 4 elements, limit is 3"},
+		{"error: bytes length", "bytes", "0x0102030405", false, "bytes is 5 bytes, limit is 4"},
+		{"error: string length", "(string)", `("abcde")`, false, "string is 5 bytes, limit is 4"},
+		{"error: json string length", "string[]", `["abcde"]`, true, "string is 5 bytes, limit is 4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parse := p.Parse
+			if tt.json {
+				parse = p.ParseJSON
+			}
+			_, err := parse(tt.blob, tt.value)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatalf("parse error: %s", err)
+				}
+				return
+			}
+			var pe *ParseError
+			if !errors.As(err, &pe) || pe.Kind != ErrLimit {
+				t.Fatalf("want ErrLimit, got %v", err)
+			}
+			assert.Equal(t, pe.Err.Error(), tt.err)
+		})
+	}
+
+	if _, err := p.ParseArguments("(uint8[],bool)", "[1,2,3,4],true"); err == nil || !strings.Contains(err.Error(), "more than 3 elements") {
+		t.Errorf("want array length error, got %v", err)
+	}
+	if _, err := p.ParseArguments("(string)", []string{strings.Repeat("a", 64)}); err == nil || !strings.Contains(err.Error(), "limit is 64") {
+		t.Errorf("want input size error, got %v", err)
+	}
+
+	// 参数列表与函数调用同样检查定长数组的长度
+	lp := NewParser(WithLimits(Limits{MaxArrayLength: 3}))
+	fixedLimit := "uint8[4] has a fixed array of 4 elements, limit is 3"
+	fragment := `{"type":"function","name":"f","inputs":[{"name":"a","type":"uint8[4]"}]}`
+	contract, err := abi.JSON(strings.NewReader("[" + fragment + "]"))
+	if err != nil {
+		t.Fatalf("abi error: %s", err)
+	}
+	for name, call := range map[string]func() error{
+		"ParseArguments": func() error { _, err := lp.ParseArguments("(uint8[4])", "[1]"); return err },
+		"ParseArguments json abi": func() error {
+			_, err := lp.ParseArguments(contract.Methods["f"].Inputs, "[1]")
+			return err
+		},
+		"EncodeCall":     func() error { _, err := lp.EncodeCall("f(uint8[4])", "[1]"); return err },
+		"EncodeCallJSON": func() error { _, err := lp.EncodeCallJSON(fragment, "[1]"); return err },
+		"EncodeNamedCall": func() error {
+			_, _, err := lp.EncodeNamedCall(contract, "f", map[string]string{"a": "[1]"})
+			return err
+		},
+	} {
+		var pe *ParseError
+		if err := call(); !errors.As(err, &pe) || pe.Kind != ErrLimit {
+			t.Errorf("%s: want ErrLimit, got %v", name, err)
+			continue
+		}
+		assert.Equal(t, pe.Err.Error(), fixedLimit, name)
+	}
+	var pe *ParseError
+	if _, err := lp.EncodeCall("f(fixed128x18[4])", "[1]"); !errors.As(err, &pe) || pe.Kind != ErrLimit {
+		t.Fatalf("want ErrLimit, got %v", err)
+	}
+	assert.Equal(t, pe.Err.Error(), "fixed128x18[4] has a fixed array of 4 elements, limit is 3")
+
+	// 默认只限制嵌套层数
+	deep := strings.Repeat("[", DefaultLimits.MaxDepth+1) + strings.Repeat("]", DefaultLimits.MaxDepth+1)
+	if _, err := NewParser().Parse("uint8"+strings.Repeat("[]", DefaultLimits.MaxDepth+1), deep); !errors.As(err, &pe) || pe.Kind != ErrLimit {
+		t.Errorf("want default depth error, got %v", err)
+	}
+	if _, err := NewParser(WithMaxDepth(0)).Parse("uint8"+strings.Repeat("[]", DefaultLimits.MaxDepth+1), deep); err != nil {
+		t.Errorf("parse error without depth limit: %s", err)
+	}
+	// MaxDepth 为 0 时嵌套层数仍不超过 maxValueDepth，更深的 value 不会递归下去
+	unbounded := NewParser(WithLimits(Limits{MaxArrayLength: 1000, MaxBytesLength: 1 << 20}))
+	_, err = unbounded.Parse("uint8[]", strings.Repeat("[", 100000))
+	if !errors.As(err, &pe) || pe.Kind != ErrLimit {
+		t.Fatalf("want depth error with zero MaxDepth, got %v", err)
+	}
+	assert.Equal(t, pe.Err.Error(), fmt.Sprintf("nesting deeper than %d", maxValueDepth))
+	// JSON 按类型逐层读取，比类型更深的 value 在第一个多余的层级报错
+	if _, err := unbounded.ParseJSON("uint8[]", strings.Repeat("[", 100000)); !errors.As(err, &pe) || pe.Kind != ErrMismatch {
+		t.Errorf("want type mismatch for deep JSON, got %v", err)
+	}
+	// 类型的嵌套层数与 Limits 无关，始终不超过 maxTypeDepth
+	deepType := strings.Repeat("(", maxTypeDepth+1) + "uint8" + strings.Repeat(")", maxTypeDepth+1)
+	if _, err := NewParser(WithMaxDepth(0)).Parse(deepType, "1"); !errors.As(err, &pe) || pe.Kind != ErrType {
+		t.Errorf("want type depth error, got %v", err)
+	}
+}
+
+// 定长数组与 tuple 按类型分配内存，很小的输入不能导致很大的分配
+func TestParse_LargeFixedArray(t *testing.T) {
+	tests := []struct {
+		name  string
+		blob  string
+		value string
+		kind  ErrorKind
+		path  string
+	}{
+		{"error: huge array in tuple", "(uint8[1000000000000])", "(1)", ErrType, ""},
+		{"error: huge nested array", "uint8[1000000000000][1]", "[[1]]", ErrType, ""},
+		{"error: overflowing array in tuple", "(uint8[1000000000000][1000000000000])", "([[1]])", ErrType, ""},
+		{"error: array over type size limit", "(uint8[100000000],bool)", "([1],true)", ErrType, ""},
+		{"error: short array in tuple", "(uint8[100000],bool)", "([1],true)", ErrLength, ".name0"},
+		{"error: short array in slice", "uint8[100000][]", "[[1],[2]]", ErrLength, "[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewParser().Parse(tt.blob, tt.value)
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("want *ParseError, got %v", err)
+			}
+			assert.Equal(t, pe.Kind, tt.kind)
+			assert.Equal(t, pe.Path, tt.path)
+		})
+	}
+
+	if _, err := EncodeCall("f((uint8[1000000000000]))", "(1)"); err == nil {
+		t.Errorf("want type size error for signature")
+	}
+	fragment := `{"type":"function","name":"f","inputs":[{"name":"a","type":"uint8[1000000000000]"}]}`
+	if _, err := EncodeCallJSON(fragment, "[1]"); err == nil {
+		t.Errorf("want type size error for JSON ABI")
+	}
+}
+
+func TestRecoverPanic(t *testing.T) {
+	parse := func() (err error) {
+		defer recoverPanic(&err)
+		var m map[string]int
+		m["a"] = 1
+		return nil
+	}
+	err := parse()
+	var pe *ParseError
+	if !errors.As(err, &pe) || !errors.Is(err, errPanic) {
+		t.Errorf("want recovered panic, got %v", err)
+	}
+}
+
+// FuzzParse 检查任意输入都不会 panic，recoverPanic 兜底的 panic 也视为失败：
+//
+//	go test -run xxx -fuzz FuzzParse -fuzztime 1m
+func FuzzParse(f *testing.F) {
+	for _, tt := range parseTests {
+		f.Add(tt.blob, tt.value)
+	}
+	f.Add("(address to,uint256[] amounts)[]", `[{"to":"0x00000000006c3852cbef3e08e8df289169ede581","amounts":["1e18",2]}]`)
+	f.Add("(string,bytes4)", `("a,\"bé😀",0x12)`)
+	f.Add("function", "0x00000000006c3852cbef3e08e8df289169ede581.transfer(address,uint256)")
+	f.Add("fixed128x18[2]", "[1.5,-0.000000000000000001]")
+	f.Add("(uint8[1000000000000])", "(1)")
+	f.Add("uint8[1000000000000][1]", "[[1]]")
+	f.Add("((uint8[1000000000000]))", "(1)")
+	f.Add("(uint8[100000000],bool)", "([1],true)")
+	f.Add("", "")
+
+	p := NewParser(WithLimits(Limits{MaxInputSize: 4096, MaxDepth: 16, MaxArrayLength: 256, MaxBytesLength: 1024}))
+	f.Fuzz(func(t *testing.T, blob, value string) {
+		for _, parse := range []func(string, string) (interface{}, error){p.Parse, p.ParseJSON} {
+			if _, err := parse(blob, value); errors.Is(err, errPanic) {
+				t.Errorf("parse %q %q: %s", blob, value, err)
+			}
+		}
+		if _, err := p.ParseArguments(blob, value); errors.Is(err, errPanic) {
+			t.Errorf("parse arguments %q %q: %s", blob, value, err)
+		}
+	})
 }
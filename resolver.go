@@ -0,0 +1,84 @@
+package go_abi_param
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common"
+	"os"
+	"strings"
+)
+
+// Resolver 把不是十六进制的地址参数解析为地址，eg: vitalik.eth、@usdc，见 SetResolver
+type Resolver interface {
+	Resolve(name string) (common.Address, error)
+}
+
+// SetResolver 设置地址参数的名字解析，值不是十六进制地址时交给 resolver 解析
+func (ap *AbiParam) SetResolver(resolver Resolver) *AbiParam {
+	ap.resolver = resolver
+	return ap
+}
+
+// resolveAddress 解析地址参数，十六进制地址按 readAddress 解析，其它交给 resolver，
+// 严格模式下解析出的零地址同样需要 AllowZero
+func resolveAddress(value string, opts *AddressOptions, resolver Resolver) (common.Address, error) {
+	if resolver == nil || strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") || common.IsHexAddress(value) {
+		return readAddress(value, opts)
+	}
+	addr, err := resolver.Resolve(value)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("abi: resolve %s: %w", value, err)
+	}
+	if opts != nil && addr == (common.Address{}) && !opts.AllowZero {
+		return common.Address{}, fmt.Errorf("abi: %s resolves to the zero address, which is not allowed", value)
+	}
+	return addr, nil
+}
+
+// AddressBook 地址簿，名字不区分大小写，@ 前缀可以省略，eg: @usdc、USDC 与 usdc 等价
+type AddressBook struct {
+	names map[string]common.Address
+}
+
+func NewAddressBook() *AddressBook {
+	return &AddressBook{names: map[string]common.Address{}}
+}
+
+// LoadAddressBook 从 JSON 文件读取地址簿，格式为 {"usdc": "0xA0b8...", "@weth": "0xC02a..."}
+func LoadAddressBook(path string) (*AddressBook, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries map[string]string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("address book %s: %s", path, err)
+	}
+
+	book := NewAddressBook()
+	for name, hex := range entries {
+		if !common.IsHexAddress(hex) {
+			return nil, fmt.Errorf("address book %s: %s is not a valid address: %s", path, name, hex)
+		}
+		book.Add(name, common.HexToAddress(hex))
+	}
+	return book, nil
+}
+
+// Add 添加或覆盖一个名字
+func (b *AddressBook) Add(name string, addr common.Address) *AddressBook {
+	b.names[addressBookKey(name)] = addr
+	return b
+}
+
+func (b *AddressBook) Resolve(name string) (common.Address, error) {
+	addr, ok := b.names[addressBookKey(name)]
+	if !ok {
+		return common.Address{}, fmt.Errorf("%s not found in address book", name)
+	}
+	return addr, nil
+}
+
+func addressBookKey(name string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "@"))
+}
@@ -0,0 +1,149 @@
+package go_abi_param
+
+import (
+	"encoding/hex"
+	"errors"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/magiconair/properties/assert"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var (
+	usdcAddr    = common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	vitalikAddr = common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045")
+)
+
+func TestAddressBook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.json")
+	if err := os.WriteFile(path, []byte(`{"usdc":"0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48","@Vitalik":"0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	book, err := LoadAddressBook(path)
+	if err != nil {
+		t.Fatalf("load address book error: %s", err)
+	}
+
+	param, _ := NewAbiParam("(address,address[])", "(@usdc,[vitalik,0x00000000006c3852cbef3e08e8df289169ede581,@USDC])")
+	got, err := param.SetResolver(book).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	assert.Equal(t, got, struct {
+		Name0 common.Address   `json:"name0"`
+		Name1 []common.Address `json:"name1"`
+	}{usdcAddr, []common.Address{vitalikAddr, common.HexToAddress("0x00000000006c3852cbef3e08e8df289169ede581"), usdcAddr}})
+
+	param, _ = NewAbiParam("address[]", "[@usdc,@dai]")
+	_, err = param.SetResolver(book).Parse()
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("want *ParseError, got %v", err)
+	}
+	assert.Equal(t, pe.Path, "[1]")
+	assert.Equal(t, pe.Token, "@dai")
+
+	// 严格模式下解析出的零地址同样需要 AllowZero
+	book.Add("zero", common.Address{})
+	strict := NewParser(WithStrict(), WithResolver(book))
+	if _, err := strict.Parse("address", "@zero"); err == nil {
+		t.Errorf("want zero address error")
+	}
+	lenient := NewParser(WithAddressOptions(AddressOptions{AllowZero: true}), WithResolver(book))
+	got, err = lenient.Parse("address", "@zero")
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	assert.Equal(t, got, common.Address{})
+
+	// 没有 resolver 时保持原有行为
+	param, _ = NewAbiParam("address", "0x00000000006c3852cbef3e08e8df289169ede581")
+	if _, err := param.Parse(); err != nil {
+		t.Errorf("parse error: %s", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"usdc":"0x1234"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadAddressBook(path); err == nil {
+		t.Errorf("want invalid address error")
+	}
+}
+
+func TestNamehash(t *testing.T) {
+	// EIP-137 中的例子
+	tests := map[string]string{
+		"":        "0000000000000000000000000000000000000000000000000000000000000000",
+		"eth":     "93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae",
+		"foo.eth": "de9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f",
+	}
+	for name, want := range tests {
+		node := namehash(name)
+		assert.Equal(t, hex.EncodeToString(node[:]), want, name)
+	}
+}
+
+// lookupCode 返回一个合约的 runtime code：calldata 中的第一个参数等于 node 时返回 addr，否则返回零地址
+func lookupCode(node [32]byte, addr common.Address) []byte {
+	code := []byte{0x60, 0x04, 0x35, 0x7f} // PUSH1 4 CALLDATALOAD PUSH32
+	code = append(code, node[:]...)
+	code = append(code,
+		0x14, 0x60, 0x32, 0x57, // EQ PUSH1 0x32 JUMPI
+		0x60, 0x00, 0x60, 0x00, 0x52, // PUSH1 0 PUSH1 0 MSTORE
+		0x60, 0x20, 0x60, 0x00, 0xf3, // PUSH1 32 PUSH1 0 RETURN
+		0x5b, 0x73, // JUMPDEST PUSH20
+	)
+	code = append(code, addr[:]...)
+	code = append(code,
+		0x60, 0x00, 0x52, // PUSH1 0 MSTORE
+		0x60, 0x20, 0x60, 0x00, 0xf3, // PUSH1 32 PUSH1 0 RETURN
+	)
+	// init code 把 runtime code 复制到内存并返回
+	init := []byte{0x60, byte(len(code)), 0x80, 0x60, 0x0b, 0x60, 0x00, 0x39, 0x60, 0x00, 0xf3}
+	return append(init, code...)
+}
+
+func TestENSResolver(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	auth, _ := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(1e18)}}, 8000000)
+	defer backend.Close()
+
+	deploy := func(code []byte) common.Address {
+		addr, _, _, err := bind.DeployContract(auth, abi.ABI{}, code, backend)
+		if err != nil {
+			t.Fatalf("deploy error: %s", err)
+		}
+		backend.Commit()
+		return addr
+	}
+	node := namehash("vitalik.eth")
+	resolverAddr := deploy(lookupCode(node, vitalikAddr))
+	registry := deploy(lookupCode(node, resolverAddr))
+
+	ens := NewENSResolver(backend, registry)
+	addr, err := ens.Resolve("Vitalik.eth")
+	if err != nil {
+		t.Fatalf("resolve error: %s", err)
+	}
+	assert.Equal(t, addr, vitalikAddr)
+
+	if _, err := ens.Resolve("nobody.eth"); err == nil || !strings.Contains(err.Error(), "no resolver") {
+		t.Errorf("want no resolver error, got %v", err)
+	}
+
+	param, _ := NewAbiParam("address[]", "[vitalik.eth,0x00000000006c3852cbef3e08e8df289169ede581]")
+	got, err := param.SetResolver(ens).Parse()
+	if err != nil {
+		t.Fatalf("parse error: %s", err)
+	}
+	assert.Equal(t, got, []common.Address{vitalikAddr, common.HexToAddress("0x00000000006c3852cbef3e08e8df289169ede581")})
+}
@@ -0,0 +1,371 @@
+package go_abi_param
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// value 字符串的语法：
+// value := item { ',' item }
+// item  := '[' [ value ] ']'    数组
+//        | '(' [ value ] ')'    tuple
+//        | '"' ... '"'          带引号的字符串，可以包含分隔符与空格，支持 \" \\ \n \uXXXX 等转义
+//        | word                 其它任意不含 [](),"的字符，紧跟的 (...) 属于 word，eg: 0xabc.transfer(address,uint256)
+// 最外层的 [] 或 () 可以省略，eg: 1,2,3 等价于 [1,2,3]
+// token 之间的空格会被忽略，word 内部与引号内的空格保留
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokLBrack
+	tokRBrack
+	tokLParen
+	tokRParen
+	tokComma
+	tokString
+	tokWord
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return "end of input"
+	case tokLBrack:
+		return "'['"
+	case tokRBrack:
+		return "']'"
+	case tokLParen:
+		return "'('"
+	case tokRParen:
+		return "')'"
+	case tokComma:
+		return "','"
+	case tokString:
+		return "string"
+	default:
+		return "word"
+	}
+}
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // token 在原始字符串中的字节偏移
+}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isDelim(c byte) bool {
+	switch c {
+	case '[', ']', '(', ')', ',', '"':
+		return true
+	}
+	return false
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) && isSpace(l.input[l.pos]) {
+		l.pos++
+	}
+	if l.pos >= len(l.input) {
+		return token{kind: tokEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	switch l.input[start] {
+	case '[':
+		l.pos++
+		return token{kind: tokLBrack, text: "[", pos: start}, nil
+	case ']':
+		l.pos++
+		return token{kind: tokRBrack, text: "]", pos: start}, nil
+	case '(':
+		l.pos++
+		return token{kind: tokLParen, text: "(", pos: start}, nil
+	case ')':
+		l.pos++
+		return token{kind: tokRParen, text: ")", pos: start}, nil
+	case ',':
+		l.pos++
+		return token{kind: tokComma, text: ",", pos: start}, nil
+	case '"':
+		return l.lexString()
+	}
+
+	for l.pos < len(l.input) && !isDelim(l.input[l.pos]) {
+		l.pos++
+	}
+	// 紧跟在 word 后的括号属于 word，eg: function 类型的 0xabc.transfer(address,uint256)
+	if l.pos < len(l.input) && l.input[l.pos] == '(' && !isSpace(l.input[l.pos-1]) {
+		end, err := matchParen(l.input, l.pos)
+		if err != nil {
+			return token{}, syntaxError(l.pos, l.input[start:], "unpaired '('")
+		}
+		l.pos = end + 1
+	}
+	// word 内部的空格保留，两端的空格属于 token 之间的分隔
+	return token{kind: tokWord, text: strings.TrimRight(l.input[start:l.pos], " \t\n\r"), pos: start}, nil
+}
+
+// lexString 读取带引号的字符串，支持与 JSON 相同的转义：
+// \" \\ \/ \b \f \n \r \t 以及 \uXXXX（包括 UTF-16 代理对）
+func (l *lexer) lexString() (token, error) {
+	start := l.pos
+	var sb strings.Builder
+	for l.pos++; l.pos < len(l.input); l.pos++ {
+		c := l.input[l.pos]
+		switch c {
+		case '"':
+			l.pos++
+			return token{kind: tokString, text: sb.String(), pos: start}, nil
+		case '\\':
+			if err := l.lexEscape(&sb); err != nil {
+				return token{}, err
+			}
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	return token{}, syntaxError(start, l.input[start:], "unterminated string")
+}
+
+// lexEscape 读取 l.pos 处的转义序列，结束时 l.pos 指向序列的最后一个字节
+func (l *lexer) lexEscape(sb *strings.Builder) error {
+	start := l.pos
+	if l.pos+1 >= len(l.input) {
+		return syntaxError(start, l.input[start:], "unterminated escape sequence")
+	}
+	l.pos++
+	switch c := l.input[l.pos]; c {
+	case '"', '\\', '/':
+		sb.WriteByte(c)
+	case 'b':
+		sb.WriteByte('\b')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'r':
+		sb.WriteByte('\r')
+	case 't':
+		sb.WriteByte('\t')
+	case 'u':
+		r, err := l.lexRune(start)
+		if err != nil {
+			return err
+		}
+		if utf16.IsSurrogate(r) {
+			// 代理对的后半部分必须紧跟着出现
+			if l.pos+2 >= len(l.input) || l.input[l.pos+1] != '\\' || l.input[l.pos+2] != 'u' {
+				return syntaxError(start, l.input[start:l.pos+1], "invalid surrogate pair")
+			}
+			l.pos += 2
+			r2, err := l.lexRune(start)
+			if err != nil {
+				return err
+			}
+			if r = utf16.DecodeRune(r, r2); r == utf8.RuneError {
+				return syntaxError(start, l.input[start:l.pos+1], "invalid surrogate pair")
+			}
+		}
+		sb.WriteRune(r)
+	default:
+		return syntaxError(start, l.input[start:l.pos+1], "invalid escape sequence")
+	}
+	return nil
+}
+
+// lexRune 读取 \u 之后的 4 位十六进制数
+func (l *lexer) lexRune(start int) (rune, error) {
+	if l.pos+4 >= len(l.input) {
+		return 0, syntaxError(start, l.input[start:], "invalid unicode escape")
+	}
+	v, err := strconv.ParseUint(l.input[l.pos+1:l.pos+5], 16, 16)
+	if err != nil {
+		return 0, syntaxError(start, l.input[start:l.pos+5], "invalid unicode escape")
+	}
+	l.pos += 4
+	return rune(v), nil
+}
+
+// unquoteString 当 value 整体是一个带引号的字符串时返回去掉引号、处理转义后的内容
+func unquoteString(value string) (string, bool) {
+	l := &lexer{input: value}
+	tok, err := l.next()
+	if err != nil || tok.kind != tokString {
+		return "", false
+	}
+	if end, err := l.next(); err != nil || end.kind != tokEOF {
+		return "", false
+	}
+	return tok.text, true
+}
+
+type nodeKind int
+
+const (
+	nodeScalar nodeKind = iota
+	nodeString
+	nodeList
+	nodeTuple
+)
+
+func (k nodeKind) String() string {
+	switch k {
+	case nodeScalar:
+		return "scalar"
+	case nodeString:
+		return "quoted string"
+	case nodeList:
+		return "array"
+	default:
+		return "tuple"
+	}
+}
+
+// node 是 value 字符串解析后的语法树
+type node struct {
+	kind  nodeKind
+	text  string  // scalar 的原文，或去掉引号后的字符串
+	pos   int     // 在原始字符串中的字节偏移
+	elems []*node // 数组与 tuple 的成员
+}
+
+type valueParser struct {
+	lex      *lexer
+	tok      token
+	depth    int // 当前所在的数组与 tuple 层数
+	limits   Limits
+	slab     []node
+	slabSize int
+}
+
+// maxNodeSlab 节点按块分配，块从 8 个节点开始翻倍，最大 maxNodeSlab，
+// 大数组中的每个元素不再单独分配一次，较短的 value 也不会浪费太多内存
+const maxNodeSlab = 128
+
+func (p *valueParser) newNode(kind nodeKind, text string, pos int) *node {
+	if len(p.slab) == 0 {
+		p.slabSize = min(max(p.slabSize*2, 8), maxNodeSlab)
+		p.slab = make([]node, p.slabSize)
+	}
+	n := &p.slab[0]
+	p.slab = p.slab[1:]
+	n.kind, n.text, n.pos = kind, text, pos
+	return n
+}
+
+func (p *valueParser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+// parseValueTree 把 value 解析为逗号分隔的最外层成员，limits 限制嵌套层数与成员个数
+func parseValueTree(value string, limits Limits) ([]*node, error) {
+	p := &valueParser{lex: &lexer{input: value}, limits: limits}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokEOF {
+		return nil, nil
+	}
+	items, err := p.parseItems()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, syntaxError(p.tok.pos, p.tok.text, "unexpected %s", p.tok.kind)
+	}
+	return items, nil
+}
+
+// parseContainer 解析 value 并返回最外层的数组或 tuple，省略了最外层括号时自动补上
+func parseContainer(value string, kind nodeKind, limits Limits) (*node, error) {
+	items, err := parseValueTree(value, limits)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 1 && items[0].kind == kind {
+		return items[0], nil
+	}
+	return &node{kind: kind, elems: items}, nil
+}
+
+func (p *valueParser) parseItems() ([]*node, error) {
+	// tuple 与小数组一般只有几个成员，预留容量避免 append 多次扩容
+	items := make([]*node, 0, 4)
+	for {
+		item, err := p.parseItem()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if p.tok.kind != tokComma {
+			return items, nil
+		}
+		if max := p.limits.MaxArrayLength; max > 0 && len(items) >= max {
+			return nil, limitError(p.tok.pos, "more than %d elements", max)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *valueParser) parseItem() (*node, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokWord, tokString:
+		kind := nodeScalar
+		if tok.kind == tokString {
+			kind = nodeString
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		return p.newNode(kind, tok.text, tok.pos), nil
+	case tokLBrack:
+		return p.parseGroup(nodeList, tokRBrack)
+	case tokLParen:
+		return p.parseGroup(nodeTuple, tokRParen)
+	default:
+		return nil, syntaxError(tok.pos, tok.text, "unexpected %s", tok.kind)
+	}
+}
+
+func (p *valueParser) parseGroup(kind nodeKind, closing tokenKind) (*node, error) {
+	n := p.newNode(kind, "", p.tok.pos)
+	p.depth++
+	defer func() { p.depth-- }()
+	if max := p.limits.maxDepth(); p.depth > max {
+		return nil, limitError(p.tok.pos, "nesting deeper than %d", max)
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != closing {
+		elems, err := p.parseItems()
+		if err != nil {
+			return nil, err
+		}
+		n.elems = elems
+	}
+	if p.tok.kind != closing {
+		return nil, syntaxError(p.tok.pos, p.tok.text, "expected %s, got %s", closing, p.tok.kind)
+	}
+	return n, p.advance()
+}
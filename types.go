@@ -1,6 +1,7 @@
 package go_abi_param
 
 import (
+	"container/list"
 	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
@@ -8,6 +9,7 @@ import (
 	"math/big"
 	"reflect"
 	"strings"
+	"sync"
 )
 
 // 老版本的数组解析只支持一维，且格式为 aaa,bbb,ccc
@@ -18,132 +20,523 @@ import (
 
 // https://github.com/ethereum/go-ethereum/blob/master/accounts/abi/type_test.go
 func (ap *AbiParam) parseParam(blob, value string) (interface{}, error) {
-	if strings.Count(value, "[") != strings.Count(value, "]") {
-		return nil, fmt.Errorf("left block count != right block count")
+	typ, err := ap.typeOf(blob)
+	if err != nil {
+		return nil, &ParseError{Kind: ErrType, Type: blob, Err: fmt.Errorf("blob to go type error: %s", err)}
 	}
+	if err := ap.checkTypeLimits(typ); err != nil {
+		return nil, err
+	}
+	ap.fixed = typ.fixed
+	if ap.json {
+		return ap.parseJSON(&typ.Type, value)
+	}
+	return ap.parseType(&typ.Type, value)
+}
 
-	// 移除用户填写的空格
-	value = strings.ReplaceAll(value, " ", "")
-
-	// value 应该支持科学技术法, eg: 1e18
-	_, match := new(big.Int).SetString(value, 10)
-	if !match {
-		x, _, err := new(big.Float).Parse(value, 10)
-		if err == nil {
-			// value 为数值类型参数
-			val, _ := x.Int(big.NewInt(0))
-			//_value = val
-			value = val.String()
+// parseType 按已解析好的 abi.Type 解析 value
+func (ap *AbiParam) parseType(typ *abi.Type, value string) (interface{}, error) {
+	switch typ.T {
+	case abi.SliceTy, abi.ArrayTy:
+		root, err := parseContainer(value, nodeList, ap.limits)
+		if err != nil {
+			return nil, withPosition(err, ap.fixed.typeName(typ), "", 0, "")
 		}
+		return ap.forEachUnpackForString(typ, root)
+	case abi.TupleTy:
+		root, err := parseContainer(value, nodeTuple, ap.limits)
+		if err != nil {
+			return nil, withPosition(err, ap.fixed.typeName(typ), "", 0, "")
+		}
+		return ap.readTuple(typ, root)
+	default:
+		// 最外层的字符串按原文解析，整体带引号时按转义规则去掉引号，eg: "a,b\n" => a,b 与换行
+		// 其他类型与列表元素一样忽略首尾空白，eg: "100\n" => 100
+		if typ.T == abi.StringTy {
+			if s, ok := unquoteString(value); ok {
+				value = s
+			}
+		} else {
+			value = strings.TrimSpace(value)
+		}
+		ret, err := ap.parseScalar(typ, value)
+		if err != nil {
+			return nil, withPosition(err, ap.fixed.typeName(typ), "", 0, value)
+		}
+		return ret, nil
 	}
+}
 
-	typ, err := abi.NewType(blob, "", nil)
-	if err != nil {
-		return nil, fmt.Errorf("blob to go type error: %s", err)
+// parseNode 按 abi.Type 解析语法树中的一个节点，错误中的路径由外层逐级补上，见 inPath
+func (ap *AbiParam) parseNode(typ *abi.Type, n *node) (interface{}, error) {
+	switch typ.T {
+	case abi.SliceTy, abi.ArrayTy:
+		return ap.forEachUnpackForString(typ, n)
+	case abi.TupleTy:
+		return ap.readTuple(typ, n)
+	default:
+		if n.kind != nodeScalar && n.kind != nodeString {
+			return nil, &ParseError{Kind: ErrMismatch, Type: ap.fixed.typeName(typ), Offset: n.pos, Err: fmt.Errorf("expected %s, got %s", ap.fixed.typeName(typ), n.kind)}
+		}
+		ret, err := ap.parseScalar(typ, n.text)
+		if err != nil {
+			return nil, withPosition(err, ap.fixed.typeName(typ), "", n.pos, n.text)
+		}
+		return ret, nil
 	}
+}
 
+// parseScalar 解析非数组、非 tuple 类型的值
+func (ap *AbiParam) parseScalar(typ *abi.Type, value string) (interface{}, error) {
 	switch typ.T {
-	case abi.SliceTy:
-		return ap.forEachUnpackForString(typ, value)
-	case abi.ArrayTy:
-		return ap.forEachUnpackForString(typ, value)
 	case abi.StringTy:
+		if err := ap.checkBytesLength(typ, len(value)); err != nil {
+			return nil, err
+		}
+		// 字符串中的空格是内容的一部分
 		return readString(value)
+	case abi.FunctionTy:
+		// 函数签名中的参数名以空格分隔
+		return readFunction(value)
+	}
+	// 移除用户填写的空格
+	value = strings.ReplaceAll(value, " ", "")
+
+	switch typ.T {
 	case abi.IntTy, abi.UintTy:
-		return readInteger(typ, value)
+		if decimals, ok := ap.fixed[typ]; ok {
+			return readFixed(*typ, ap.fixed.typeName(typ), value, decimals)
+		}
+		return readInteger(*typ, value, ap.decimals, ap.units)
 	case abi.BoolTy:
 		return readBool(value)
 	case abi.AddressTy:
-		return readAddress(value)
+		return resolveAddress(value, ap.address, ap.resolver)
 	case abi.HashTy:
 		return common.HexToHash(value), nil
 	case abi.BytesTy:
-		return readBytes(value)
-	case abi.FixedBytesTy:
-		bytesVal, dErr := hexutil.Decode(value)
-		if dErr != nil {
+		// 0x 之后每两个字符为一个字节，先按长度检查，避免解码超长的输入
+		if err := ap.checkBytesLength(typ, (len(value)-2)/2); err != nil {
 			return nil, err
 		}
-		return readFixedBytes(typ, bytesVal)
-	case abi.FunctionTy:
+		return readBytes(value)
+	case abi.FixedBytesTy:
 		bytesVal, dErr := hexutil.Decode(value)
 		if dErr != nil {
-			return nil, err
+			return nil, dErr
 		}
-		return readFunctionType(typ, bytesVal)
+		return readFixedBytes(*typ, bytesVal, ap.padding)
 	default:
 		return nil, fmt.Errorf("abi: unknown type %v", typ.T)
 	}
 }
 
-func getType(t abi.Type) string {
-	switch t.T {
-	case abi.IntTy:
-		return fmt.Sprintf("%s%d", "int", t.Size)
-	case abi.UintTy:
-		return fmt.Sprintf("%s%d", "uint", t.Size)
-	case abi.BoolTy:
-		return "bool"
-	case abi.StringTy:
-		return "string"
-	case abi.SliceTy:
-		//return fmt.Sprintf("%s%s", t.Elem.GetType().String(), "[]")
-		return fmt.Sprintf("%s%s", t.Elem.String(), "[]")
+// checkBytesLength 检查 bytes 或 string 的字节数是否超出 MaxBytesLength
+func (ap *AbiParam) checkBytesLength(typ *abi.Type, size int) error {
+	if max := ap.limits.MaxBytesLength; max > 0 && size > max {
+		return limitError(0, "%s is %d bytes, limit is %d", typ.String(), size, max)
+	}
+	return nil
+}
+
+// paramType 是 newType 解析出的类型，其中的定点数记录在 fixed 中，见 fixedTypes
+type paramType struct {
+	abi.Type
+	fixed     fixedTypes
+	maxLength int // 定长数组的最大长度，见 Limits.MaxArrayLength
+}
+
+// String 返回类型名，其中的定点数按 fixedMxN 书写
+func (pt *paramType) String() string {
+	return pt.fixed.typeName(&pt.Type)
+}
+
+// typeCache 缓存解析过的类型，所有 Parser 与 AbiParam 共用，
+// 类型构造完成后不再修改，可以在多个 goroutine 中同时读取
+var typeCache = newTypeLRU(maxCachedTypes)
+
+const (
+	maxCachedTypes = 4096 // 缓存的类型数量上限，超过后淘汰最久未使用的类型
+	maxCachedBlob  = 1024 // 更长的 blob 不缓存，避免少数超长的 blob 占用大量内存
+)
+
+// typeLRU 有容量上限的类型缓存，超过容量时淘汰最久未使用的类型
+type typeLRU struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element // blob => order 中的元素
+	order    *list.List               // 最近使用的在前，元素为 *typeEntry
+}
+
+type typeEntry struct {
+	blob string
+	typ  *paramType
+}
+
+func newTypeLRU(capacity int) *typeLRU {
+	return &typeLRU{capacity: capacity, items: map[string]*list.Element{}, order: list.New()}
+}
+
+func (c *typeLRU) get(blob string) (*paramType, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[blob]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*typeEntry).typ, true
+}
+
+func (c *typeLRU) add(blob string, typ *paramType) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[blob]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+	c.items[blob] = c.order.PushFront(&typeEntry{blob: blob, typ: typ})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*typeEntry).blob)
+	}
+}
+
+func (c *typeLRU) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// cachedType 与 newType 相同，但结果会被缓存
+func cachedType(blob string) (*paramType, error) {
+	if typ, ok := typeCache.get(blob); ok {
+		return typ, nil
+	}
+	typ, err := newType(blob)
+	if err != nil {
+		return nil, err
+	}
+	if len(blob) <= maxCachedBlob {
+		typeCache.add(blob, typ)
+	}
+	return typ, nil
+}
+
+// newType 在 abi.NewType 的基础上支持 tuple 写法：
+// (address,uint256)
+// (address,uint256)[]
+// ((uint8,bytes32),string)[2]
+// 成员可以带名字，如 (address to,uint256 amount)，未命名的成员按 go-ethereum ParseSelector 的习惯命名为 name0、name1...
+func newType(blob string) (*paramType, error) {
+	arg, err := parseTypeMarshaling(strings.TrimSpace(blob), "", 0)
+	if err != nil {
+		return nil, err
+	}
+	typ := &paramType{}
+	if typ.fixed, typ.maxLength, err = buildType(&typ.Type, arg); err != nil {
+		return nil, err
+	}
+	return typ, nil
+}
+
+// buildType 构造 arg 对应的类型并写入 typ，返回其中的定点数与定长数组的最大长度，
+// 超出 maxTypeSize 的类型按错误返回
+func buildType(typ *abi.Type, arg abi.ArgumentMarshaling) (fixed fixedTypes, maxLength int, err error) {
+	// tuple 中超大的定长数组会让 reflect.ArrayOf / StructOf panic
+	defer func() {
+		if r := recover(); r != nil {
+			fixed, maxLength, err = nil, 0, fmt.Errorf("unsupported arg type %s: %v", arg.Type, r)
+		}
+	}()
+	if fixed, err = newTypeWithFixed(typ, arg.Type, arg.InternalType, fillNames(arg.Components)); err != nil {
+		return nil, 0, err
+	}
+	if _, maxLength, err = checkTypeSize(typ); err != nil {
+		return nil, 0, err
+	}
+	return fixed, maxLength, nil
+}
+
+// maxTypeSize 定长数组与 tuple 在内存中的最大字节数，它们按类型一次分配，与输入的长度无关，
+// 太大的类型在解析前拒绝，eg: uint8[1000000000000]
+const maxTypeSize = 1 << 24
+
+// checkTypeSize 检查 typ 及其成员在内存中的大小是否超出 maxTypeSize，返回 typ 的大小与其中定长数组的最大长度，
+// 数组的大小按元素计算，不调用 GetType，避免 reflect.ArrayOf 对溢出的大小 panic
+func checkTypeSize(typ *abi.Type) (size uintptr, maxLength int, err error) {
+	switch typ.T {
 	case abi.ArrayTy:
-		return fmt.Sprintf("%s[%d]", t.Elem.String(), t.Size)
+		elem, maxLength, err := checkTypeSize(typ.Elem)
+		if err != nil {
+			return 0, 0, err
+		}
+		if typ.Size < 0 || (elem > 0 && uintptr(typ.Size) > maxTypeSize/elem) {
+			return 0, 0, fmt.Errorf("type %s is larger than %d bytes", typ.String(), maxTypeSize)
+		}
+		if typ.Size > maxLength {
+			maxLength = typ.Size
+		}
+		return uintptr(typ.Size) * elem, maxLength, nil
+	case abi.SliceTy:
+		_, maxLength, err := checkTypeSize(typ.Elem)
+		return reflect.TypeOf([]byte(nil)).Size(), maxLength, err
 	case abi.TupleTy:
-		return "tuple"
-	case abi.AddressTy:
-		return "address"
-	//case abi.FixedBytesTy, abi.BytesTy:
-	case abi.BytesTy:
-		return "bytes"
-	case abi.FixedBytesTy:
-		return "bytes32"
-	case abi.HashTy:
-		return reflect.ArrayOf(32, reflect.TypeOf(byte(0))).String()
-	case abi.FixedPointTy:
-		return reflect.ArrayOf(32, reflect.TypeOf(byte(0))).String()
-	case abi.FunctionTy:
-		return "function"
-	default:
-		panic("Invalid type")
+		for _, elem := range typ.TupleElems {
+			_, n, err := checkTypeSize(elem)
+			if err != nil {
+				return 0, 0, err
+			}
+			if n > maxLength {
+				maxLength = n
+			}
+		}
+		if size = typ.TupleType.Size(); size > maxTypeSize {
+			return 0, 0, fmt.Errorf("type %s is larger than %d bytes", typ.String(), maxTypeSize)
+		}
+		return size, maxLength, nil
+	}
+	return typ.GetType().Size(), 0, nil
+}
+
+// checkArgumentsSize 对参数列表中的每个类型执行 checkTypeSize，用于不经过 newType 的 abi.Arguments，eg: JSON ABI
+func checkArgumentsSize(args abi.Arguments) error {
+	for _, arg := range args {
+		if _, _, err := checkTypeSize(&arg.Type); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func readInteger(typ abi.Type, value string) (interface{}, error) {
+// checkTypeLimits 检查类型中定长数组的长度是否超出 MaxArrayLength
+func (ap *AbiParam) checkTypeLimits(typ *paramType) error {
+	if max := ap.limits.MaxArrayLength; max > 0 && typ.maxLength > max {
+		return limitError(0, "%s has a fixed array of %d elements, limit is %d", typ.String(), typ.maxLength, max)
+	}
+	return nil
+}
+
+// checkArgumentLimits 对参数列表中的每个类型执行 checkTypeLimits，fixed 为其中的定点数，用于错误信息中的类型名
+func (ap *AbiParam) checkArgumentLimits(args abi.Arguments, fixed fixedTypes) error {
+	for _, arg := range args {
+		_, maxLength, err := checkTypeSize(&arg.Type)
+		if err != nil {
+			return err
+		}
+		if err := ap.checkTypeLimits(&paramType{Type: arg.Type, fixed: fixed, maxLength: maxLength}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillNames 为未命名的 tuple 成员生成名字，abi.NewType 不接受匿名成员
+func fillNames(components []abi.ArgumentMarshaling) []abi.ArgumentMarshaling {
+	for i := range components {
+		if components[i].Name == "" {
+			components[i].Name = fmt.Sprintf("name%d", i)
+		}
+		fillNames(components[i].Components)
+	}
+	return components
+}
+
+// maxTypeDepth 类型的最大嵌套层数（tuple 与数组维度），解析类型的开销随层数平方增长，
+// 与 Limits 无关，任何 Parser 都不接受更深的类型
+const maxTypeDepth = 128
+
+// maxValueDepth value 的最大嵌套层数，比 maxTypeDepth 多出参数列表最外层的括号
+const maxValueDepth = maxTypeDepth + 1
+
+// parseTypeMarshaling 把 blob 转换为 abi.NewType 需要的参数，depth 为外层已有的嵌套层数
+func parseTypeMarshaling(blob, name string, depth int) (abi.ArgumentMarshaling, error) {
+	if !strings.HasPrefix(blob, "(") {
+		if depth+strings.Count(blob, "[") > maxTypeDepth {
+			return abi.ArgumentMarshaling{}, fmt.Errorf("type nesting deeper than %d", maxTypeDepth)
+		}
+		return abi.ArgumentMarshaling{Name: name, Type: blob, InternalType: blob}, nil
+	}
+
+	end, err := matchParen(blob, 0)
+	if err != nil {
+		return abi.ArgumentMarshaling{}, err
+	}
+	// tuple 后面只允许跟数组维度，如 [] [2][]
+	suffix := blob[end+1:]
+	if strings.Trim(suffix, "[]0123456789") != "" {
+		return abi.ArgumentMarshaling{}, fmt.Errorf("invalid tuple type suffix %q", suffix)
+	}
+	depth += 1 + strings.Count(suffix, "[")
+	if depth > maxTypeDepth {
+		return abi.ArgumentMarshaling{}, fmt.Errorf("type nesting deeper than %d", maxTypeDepth)
+	}
+
+	parts, err := splitTopLevel(blob[1:end])
+	if err != nil {
+		return abi.ArgumentMarshaling{}, err
+	}
+	if len(parts) == 1 && strings.TrimSpace(parts[0]) == "" {
+		return abi.ArgumentMarshaling{}, fmt.Errorf("empty tuple type %q", blob)
+	}
+
+	components := make([]abi.ArgumentMarshaling, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		compName := ""
+		// 成员名写在类型之后，以空格分隔
+		if idx := strings.LastIndexAny(part, " \t"); idx > 0 && idx > strings.LastIndex(part, ")") {
+			compName = part[idx+1:]
+			part = strings.TrimSpace(part[:idx])
+		}
+		comp, err := parseTypeMarshaling(part, compName, depth)
+		if err != nil {
+			return abi.ArgumentMarshaling{}, err
+		}
+		components = append(components, comp)
+	}
+	return abi.ArgumentMarshaling{Name: name, Type: "tuple" + suffix, InternalType: "tuple" + suffix, Components: components}, nil
+}
+
+// matchParen 返回 blob[start] 处的 ( 或 [ 对应的闭合位置
+func matchParen(blob string, start int) (int, error) {
+	var stack []byte
+	for i := start; i < len(blob); i++ {
+		switch blob[i] {
+		case '(':
+			stack = append(stack, ')')
+		case '[':
+			stack = append(stack, ']')
+		case ')', ']':
+			if len(stack) == 0 || stack[len(stack)-1] != blob[i] {
+				return 0, fmt.Errorf("unpaired %c at %d", blob[i], i)
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unpaired block")
+}
+
+// splitTopLevel 按最外层的逗号切分，忽略 () 与 [] 内部的逗号
+func splitTopLevel(blob string) ([]string, error) {
+	var (
+		parts []string
+		depth int
+		last  int
+	)
+	for i := 0; i < len(blob); i++ {
+		switch blob[i] {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unpaired %c at %d", blob[i], i)
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, blob[last:i])
+				last = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unpaired block")
+	}
+	return append(parts, blob[last:]), nil
+}
+
+// readInteger 读取 intN/uintN，value 支持整数字面量、小数与单位（见 readAmount），
+// 并按 abi 的取值范围校验：
+// uintN: [0, 2^N-1]
+// intN:  [-2^(N-1), 2^(N-1)-1]
+func readInteger(typ abi.Type, value string, decimals int, units map[string]int) (interface{}, error) {
+	bv, err := readAmount(value, decimals, units)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkIntegerRange(bv, typ.T == abi.IntTy, typ.Size); err != nil {
+		return nil, err
+	}
+	return toInteger(typ, bv), nil
+}
+
+// toInteger 把已检查过范围的 bv 转换为 typ 对应的 go 类型，64 位以内为 int8...uint64，其它为 *big.Int
+func toInteger(typ abi.Type, bv *big.Int) interface{} {
 	if typ.T == abi.UintTy {
 		switch typ.Size {
 		case 8:
-			return readUint8(value)
+			return uint8(bv.Uint64())
 		case 16:
-			return readUint16(value)
+			return uint16(bv.Uint64())
 		case 32:
-			return readUint32(value)
+			return uint32(bv.Uint64())
 		case 64:
-			return readUint64(value)
+			return bv.Uint64()
 		default:
-			return readBigInt(value)
+			return bv
 		}
 	}
 
 	// int
 	switch typ.Size {
 	case 8:
-		return readInt8(value)
+		return int8(bv.Int64())
 	case 16:
-		return readInt16(value)
+		return int16(bv.Int64())
 	case 32:
-		return readInt32(value)
+		return int32(bv.Int64())
 	case 64:
-		return readInt64(value)
+		return bv.Int64()
 	default:
-		ret, _ := new(big.Int).SetString(value, 10)
-		if ret.Bit(255) == 1 {
-			ret.Add(abi.MaxUint256, new(big.Int).Neg(ret))
-			ret.Add(ret, common.Big1)
-			ret.Neg(ret)
-		}
-		return ret, nil
+		return bv
+	}
+}
+
+func checkIntegerRange(v *big.Int, signed bool, size int) error {
+	if size <= 0 || size > 256 || size%8 != 0 {
+		return fmt.Errorf("abi: invalid integer type %s", integerName(signed, size))
+	}
+
+	min, max := integerBounds(signed, size)
+	if v.Cmp(max) > 0 {
+		return rangeError("abi: value %s overflows %s, max is %s", v, integerName(signed, size), max)
+	}
+	if v.Cmp(min) < 0 {
+		return rangeError("abi: value %s underflows %s, min is %s", v, integerName(signed, size), min)
+	}
+	return nil
+}
+
+func integerName(signed bool, size int) string {
+	if signed {
+		return fmt.Sprintf("int%d", size)
+	}
+	return fmt.Sprintf("uint%d", size)
+}
+
+// boundsTable 预先计算的 int8...int256、uint8...uint256 取值范围，下标为 [signed][size/8]
+var boundsTable = func() (t [2][33][2]*big.Int) {
+	for size := 8; size <= 256; size += 8 {
+		max := new(big.Int).Lsh(common.Big1, uint(size))
+		t[0][size/8] = [2]*big.Int{new(big.Int), max.Sub(max, common.Big1)}
+
+		max = new(big.Int).Lsh(common.Big1, uint(size-1))
+		min := new(big.Int).Neg(max)
+		t[1][size/8] = [2]*big.Int{min, max.Sub(max, common.Big1)}
+	}
+	return t
+}()
+
+// integerBounds 返回 intN/uintN 的最小值与最大值，size 必须是 8 的倍数，返回值是共享的，不能修改
+func integerBounds(signed bool, size int) (min, max *big.Int) {
+	b := boundsTable[0][size/8]
+	if signed {
+		b = boundsTable[1][size/8]
 	}
+	return b[0], b[1]
 }
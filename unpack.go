@@ -2,14 +2,12 @@ package go_abi_param
 
 import (
 	"encoding/binary"
-	"encoding/json"
 	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"math/big"
 	"reflect"
-	"strconv"
 	"strings"
 )
 
@@ -18,187 +16,254 @@ import (
 // [1,2,3]
 // [[1,2],[3,4]]   [1,2],[3,4]
 // [[[[1,2],[11,22]],[3,4]]]
-func fmtToBlock(originVal string) string {
-	if !hasBlock(originVal) {
-		//return originVal
-		return fmt.Sprintf("%s%s%s", "[", originVal, "]")
+// 语法树由 parseValueTree 生成，这里按 abi.Type 逐层遍历
+func (ap *AbiParam) forEachUnpackForString(t *abi.Type, n *node) (interface{}, error) {
+	if err := ap.checkList(t, n); err != nil {
+		return nil, err
 	}
-	return originVal
-}
-
-func hasBlock(originVal string) bool {
-	return originVal[:1] == "[" && originVal[len(originVal)-1:] == "]"
-}
+	output := n.elems
 
-func parseUnpackString(value string) ([]interface{}, error) {
-	value = fmtToBlock(value)
-	if strings.Count(value, "[") != strings.Count(value, "]") {
-		return nil, fmt.Errorf("unpaired block")
+	// this value will become our slice or our array, depending on the type
+	var refSlice reflect.Value
+	goType := t.GetType()
+	size := goType.Size()
+	if t.T == abi.SliceTy {
+		size = uintptr(len(output)) * goType.Elem().Size()
+	}
+	if err := ap.checkAlloc(t, n, size); err != nil {
+		return nil, err
 	}
 
-	// 可能传入可能值，不一定是非标准的字符串，所以需要将其转换为字符串，再使用 json 包解析
-	var _value []byte
-	for i, _v := range []byte(value) {
-		if _v == '[' {
-			if []byte(value)[i+1] == '[' {
-				_value = append(_value, _v)
-				continue
-			}
-			_value = append(_value, _v, '"')
-			continue
-		} else if _v == '"' {
-			_value = append(_value, _v)
-			continue
-		} else if _v == ']' {
-			if len([]byte(value)) > i+1 && []byte(value)[i+1] == ']' {
-				_value = append(_value, _v)
-				continue
-			}
-			_value = append(_value, '"', _v)
-			continue
-		} else if _v == ',' {
-			_value = append(_value, '"', _v, '"')
-			continue
-		} else {
-			if len([]byte(value)) > i+1 && []byte(value)[i+1] == ']' {
-				_value = append(_value, _v, '"')
-				continue
-			}
-			_value = append(_value, _v)
-		}
+	if t.T == abi.SliceTy {
+		// declare our slice
+		refSlice = reflect.MakeSlice(goType, len(output), len(output))
+	} else {
+		// declare our array
+		refSlice = reflect.New(goType).Elem()
 	}
-	value = strings.ReplaceAll(string(_value), `""`, `"`)
-	value = strings.ReplaceAll(value, `]",`, `],`)
-	value = strings.ReplaceAll(value, `,"[`, `,[`)
-	value = strings.ReplaceAll(value, `]"]`, `]]`)
 
-	var parsedData interface{}
-	err := json.Unmarshal([]byte(value), &parsedData)
-	if err != nil {
-		return nil, err
+	if ap.logging() {
+		ap.logger.Debugf("nest type: %s", ap.fixed.typeName(t.Elem))
 	}
+	for i, opVal := range output {
 
-	if reflect.TypeOf(parsedData).String() != "[]interface {}" {
-		return nil, fmt.Errorf("parsed data type is interface should be []interface {]")
+		inter, err := ap.parseNode(t.Elem, opVal)
+		if err != nil {
+			return nil, inPath(err, indexPath(i))
+		}
+		refSlice.Index(i).Set(reflect.ValueOf(inter))
 	}
-	return parsedData.([]interface{}), err
+	return refSlice.Interface(), nil
 }
 
-func (ap *AbiParam) forEachUnpackForString(t abi.Type, originVal string) (interface{}, error) {
-	output, err := parseUnpackString(originVal)
-	if err != nil {
+// readTuple 解析 tuple，格式为 (0xabc,100)、((1,0x01),abc)
+func (ap *AbiParam) readTuple(t *abi.Type, n *node) (interface{}, error) {
+	if err := ap.checkTuple(t, n); err != nil {
 		return nil, err
 	}
-
-	if t.Size < 0 {
-		return nil, fmt.Errorf("cannot parse input array, size is negative (%d)", t.Size)
+	if err := ap.checkAlloc(t, n, t.TupleType.Size()); err != nil {
+		return nil, err
 	}
 
-	if t.Size > len(output) {
-		return nil, fmt.Errorf("abi: cannot marshal in to go array: offset %d would go over slice boundary (len=%d)", len(output), t.Size)
+	// 生成 go-ethereum 所需的匿名结构体，字段顺序与 TupleElems 一致
+	tuple := reflect.New(t.TupleType).Elem()
+	for i, field := range n.elems {
+		inter, err := ap.parseNode(t.TupleElems[i], field)
+		if err != nil {
+			return nil, inPath(err, "."+t.TupleRawNames[i])
+		}
+		tuple.Field(i).Set(reflect.ValueOf(inter))
 	}
+	return tuple.Interface(), nil
+}
 
-	// this value will become our slice or our array, depending on the type
-	var refSlice reflect.Value
+// checkList 检查 n 是否为数组，定长数组的元素个数必须与类型一致
+func (ap *AbiParam) checkList(t *abi.Type, n *node) error {
+	if n.kind != nodeList {
+		return &ParseError{Kind: ErrMismatch, Type: ap.fixed.typeName(t), Offset: n.pos, Token: n.text, Err: fmt.Errorf("expected array, got %s", n.kind)}
+	}
+	if t.T == abi.ArrayTy && t.Size != len(n.elems) {
+		return &ParseError{Kind: ErrLength, Type: ap.fixed.typeName(t), Offset: n.pos, Err: fmt.Errorf("want %d elements, got %d", t.Size, len(n.elems))}
+	}
+	return nil
+}
 
-	if t.T == abi.SliceTy {
-		// reset slice size
-		t.Size = len(output)
-		// declare our slice
-		refSlice = reflect.MakeSlice(t.GetType(), t.Size, t.Size)
-	} else if t.T == abi.ArrayTy {
-		// declare our array
-		refSlice = reflect.New(t.GetType()).Elem()
-	} else {
-		return nil, fmt.Errorf("abi: invalid type in array/slice unpacking stage")
+// checkTuple 检查 n 是否为 tuple，成员个数必须与类型一致
+func (ap *AbiParam) checkTuple(t *abi.Type, n *node) error {
+	if n.kind != nodeTuple {
+		return &ParseError{Kind: ErrMismatch, Type: ap.fixed.typeName(t), Offset: n.pos, Token: n.text, Err: fmt.Errorf("expected tuple, got %s", n.kind)}
 	}
+	if len(n.elems) != len(t.TupleElems) {
+		return &ParseError{Kind: ErrLength, Type: ap.fixed.typeName(t), Offset: n.pos, Err: fmt.Errorf("want %d fields, got %d", len(t.TupleElems), len(n.elems))}
+	}
+	return nil
+}
 
-	for i := 0; i < t.Size; i++ {
-		ap.logger.Debugf("nest type: %s", getType(*t.Elem))
+// checkShapeSize 一次分配超过这个字节数时先检查 checkShape
+const checkShapeSize = 1 << 16
 
-		//opVal := unpackDynamicData(t.Size, output)
-		opVal := ""
-		if reflect.TypeOf(output).Kind() == reflect.String {
-			opVal = output[i].(string)
-		} else {
-			opVal = unpackDynamicData(output[i])
-		}
-		inter, err := ap.parseParam(getType(*t.Elem), opVal)
-		if err != nil {
-			return nil, err
-		}
-		refSlice.Index(i).Set(reflect.ValueOf(inter))
+// checkAlloc 在为 n 分配 size 字节之前检查语法树的形状。定长数组与 tuple 的内存按类型分配，
+// 与输入的长度无关，eg: (uint8[1000000000000]) 的 value 为 (1) 时，不检查就会先分配再报错
+func (ap *AbiParam) checkAlloc(t *abi.Type, n *node, size uintptr) error {
+	if size <= checkShapeSize {
+		return nil
 	}
-	return refSlice.Interface(), nil
+	return ap.checkShape(t, n)
 }
 
-func unpackDynamicData(ov interface{}) string {
-	var _val string
-
-	iValType := reflect.TypeOf(ov).String()
-	switch iValType {
-	case "string":
-		return ov.(string)
-	case "[]interface {}":
-		var _ovArr []string
-		for i, _ov := range ov.([]interface{}) {
-			_dData := unpackDynamicData(_ov)
-			// replace to the original value
-			if i == 0 {
-				_dData = "[" + _dData
+// checkShape 检查 n 及其成员中每一层数组与 tuple 的元素个数是否与类型一致
+func (ap *AbiParam) checkShape(t *abi.Type, n *node) error {
+	switch t.T {
+	case abi.SliceTy, abi.ArrayTy:
+		if err := ap.checkList(t, n); err != nil {
+			return err
+		}
+		// 元素为标量时内存与元素个数成正比，不需要再检查
+		if t.Elem.T != abi.SliceTy && t.Elem.T != abi.ArrayTy && t.Elem.T != abi.TupleTy {
+			return nil
+		}
+		for i, elem := range n.elems {
+			if err := ap.checkShape(t.Elem, elem); err != nil {
+				return inPath(err, indexPath(i))
 			}
-
-			// replace to the original value
-			if i == len(ov.([]interface{}))-1 {
-				_dData = _dData + "]"
+		}
+	case abi.TupleTy:
+		if err := ap.checkTuple(t, n); err != nil {
+			return err
+		}
+		for i, field := range n.elems {
+			if err := ap.checkShape(t.TupleElems[i], field); err != nil {
+				return inPath(err, "."+t.TupleRawNames[i])
 			}
-			_ovArr = append(_ovArr, _dData)
 		}
-		_val = strings.Join(_ovArr, ",")
 	}
-	return _val
+	return nil
 }
 
 // readFixedBytes uses reflection to create a fixed array to be read from.
-func readFixedBytes(t abi.Type, word []byte) (interface{}, error) {
+// word 的长度必须等于 t.Size，较短时按 padding 补零，较长时总是报错
+func readFixedBytes(t abi.Type, word []byte, padding Padding) (interface{}, error) {
 	if t.T != abi.FixedBytesTy {
 		return nil, fmt.Errorf("abi: invalid type in call to make fixed byte array")
 	}
+	if len(word) > t.Size || (len(word) < t.Size && padding == PadNone) {
+		return nil, &ParseError{Kind: ErrLength, Err: fmt.Errorf("abi: %s needs %d bytes, got %d", t.String(), t.Size, len(word))}
+	}
 	// convert
 	array := reflect.New(t.GetType()).Elem()
 
-	reflect.Copy(array, reflect.ValueOf(word[0:t.Size]))
+	offset := 0
+	if padding == PadLeft {
+		offset = t.Size - len(word)
+	}
+	reflect.Copy(array.Slice(offset, t.Size), reflect.ValueOf(word))
 	return array.Interface(), nil
 }
 
-// readFunctionType enforces that standard by always presenting it as a 24-array (address + sig = 24 bytes)
-func readFunctionType(t abi.Type, word []byte) (funcTy [24]byte, err error) {
-	if t.T != abi.FunctionTy {
-		return [24]byte{}, fmt.Errorf("abi: invalid type in call to make function type byte array")
+// readFunction 解析 function 类型的值，返回 go-ethereum 需要的 [24]byte（address + selector）
+// 支持的写法：
+// 0x00000000006c3852cbef3e08e8df289169ede581:0xa9059cbb
+// 0x00000000006c3852cbef3e08e8df289169ede581.transfer(address,uint256)
+// 0x00000000006c3852cbef3e08e8df289169ede581a9059cbb（24 字节）或右侧补零后的 32 字节
+func readFunction(value string) (funcTy [24]byte, err error) {
+	value = strings.TrimSpace(value)
+	if idx := strings.IndexAny(value, ":."); idx >= 0 {
+		addr, err := hexutil.Decode(strings.TrimSpace(value[:idx]))
+		if err != nil || len(addr) != common.AddressLength {
+			return funcTy, fmt.Errorf("abi: invalid function address %q", value[:idx])
+		}
+		var selector []byte
+		if value[idx] == ':' {
+			selector, err = hexutil.Decode(strings.TrimSpace(value[idx+1:]))
+			if err != nil || len(selector) != 4 {
+				return funcTy, fmt.Errorf("abi: invalid function selector %q", value[idx+1:])
+			}
+		} else {
+			method, _, err := parseSignature(value[idx+1:])
+			if err != nil {
+				return funcTy, err
+			}
+			selector = method.ID
+		}
+		copy(funcTy[:], addr)
+		copy(funcTy[common.AddressLength:], selector)
+		return funcTy, nil
 	}
-	if garbage := binary.BigEndian.Uint64(word[24:32]); garbage != 0 {
-		err = fmt.Errorf("abi: got improperly encoded function type, got %v", word)
-	} else {
+
+	word, err := hexutil.Decode(value)
+	if err != nil {
+		return funcTy, err
+	}
+	return readFunctionType(word)
+}
+
+// readFunctionType enforces that standard by always presenting it as a 24-array (address + sig = 24 bytes)
+func readFunctionType(word []byte) (funcTy [24]byte, err error) {
+	switch {
+	case len(word) == 24:
+		copy(funcTy[:], word)
+	case len(word) == 32 && binary.BigEndian.Uint64(word[24:32]) == 0:
 		copy(funcTy[:], word[0:24])
+	default:
+		err = fmt.Errorf("abi: got improperly encoded function type, got %v", word)
 	}
 	return
 }
 
 func readBigInt(value string) (*big.Int, error) {
-	v, ok := big.NewInt(0).SetString(value, 10)
+	v, ok := parseIntegerLiteral(value)
 	if !ok {
 		return nil, fmt.Errorf("param %s can not convent to int", value)
 	}
 	return v, nil
 }
 
-func readAddress(value string) (common.Address, error) {
+// readAddress 解析地址，opts 为空时与 common.HexToAddress 一致，opts 非空时见 SetStrictAddress
+func readAddress(value string, opts *AddressOptions) (common.Address, error) {
+	if opts != nil {
+		return readStrictAddress(value, opts)
+	}
 	if value == "" {
 		return common.Address{}, fmt.Errorf("can't convent param %s to address", value)
 	}
+	// 完整的 0x 开头 20 字节地址直接解码，其它写法交给 HexToAddress 截断或补零
+	var addr common.Address
+	if len(value) == 2+2*common.AddressLength && (value[:2] == "0x" || value[:2] == "0X") {
+		if decodeHexString(addr[:], value[2:]) {
+			return addr, nil
+		}
+	}
 	return common.HexToAddress(value), nil
 }
 
+// decodeHexString 把 len(dst)*2 个十六进制字符解码到 dst，不需要先转换为 []byte
+func decodeHexString(dst []byte, s string) bool {
+	if len(s) != len(dst)*2 {
+		return false
+	}
+	for i := range dst {
+		hi, ok1 := hexNibble(s[2*i])
+		lo, ok2 := hexNibble(s[2*i+1])
+		if !ok1 || !ok2 {
+			return false
+		}
+		dst[i] = hi<<4 | lo
+	}
+	return true
+}
+
+func hexNibble(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
 // readBool reads a bool.
 func readBool(word string) (bool, error) {
 	switch word {
@@ -211,70 +276,6 @@ func readBool(word string) (bool, error) {
 	}
 }
 
-func readInt8(value string) (int8, error) {
-	bv, err := readBigInt(value)
-	if err != nil {
-		return 0, err
-	}
-	return int8(bv.Int64()), nil
-}
-
-func readUint8(value string) (uint8, error) {
-	bv, err := readBigInt(value)
-	if err != nil {
-		return 0, err
-	}
-	return uint8(bv.Int64()), nil
-}
-
-func readInt16(value string) (int16, error) {
-	v, err := strconv.ParseInt(value, 10, 16)
-	if err != nil {
-		return 0, err
-	}
-	return int16(v), nil
-}
-
-func readUint16(value string) (uint16, error) {
-	v, err := strconv.ParseUint(value, 10, 16)
-	if err != nil {
-		return 0, err
-	}
-	return uint16(v), nil
-}
-
-func readInt32(value string) (int32, error) {
-	v, err := strconv.ParseInt(value, 10, 32)
-	if err != nil {
-		return 0, err
-	}
-	return int32(v), nil
-}
-
-func readUint32(value string) (uint32, error) {
-	v, err := strconv.ParseUint(value, 10, 32)
-	if err != nil {
-		return 0, err
-	}
-	return uint32(v), nil
-}
-
-func readInt64(value string) (int64, error) {
-	v, err := strconv.ParseInt(value, 10, 64)
-	if err != nil {
-		return 0, err
-	}
-	return v, nil
-}
-
-func readUint64(value string) (uint64, error) {
-	v, err := strconv.ParseUint(value, 10, 64)
-	if err != nil {
-		return 0, err
-	}
-	return v, nil
-}
-
 func readBytes(value string) ([]byte, error) {
 	return hexutil.Decode(value)
 }